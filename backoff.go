@@ -0,0 +1,59 @@
+package hardy
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// JitterFunc defines the function that computes the jitter added to an interval, from the interval computed by the
+// backoff. The jitter might be negative, so the interval is shortened.
+type JitterFunc func(base time.Duration) time.Duration
+
+// FullJitter is the JitterFunc that spreads the intervals across the whole backoff, so each one is a random duration
+// up to the interval computed by the backoff.
+func FullJitter(base time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	return -time.Duration(rand.Int63n(int64(base)))
+}
+
+// EqualJitter is the JitterFunc that keeps half of the backoff, so each interval is a random duration between the half
+// of the interval computed by the backoff and the whole of it.
+func EqualJitter(base time.Duration) time.Duration {
+	if half := base / 2; half > 0 {
+		return -time.Duration(rand.Int63n(int64(half)))
+	}
+	return 0
+}
+
+// maxDuration is the longest time.Duration, which the intervals saturate at instead of overflowing.
+const maxDuration = time.Duration(math.MaxInt64)
+
+// ComputeBackoff computes the interval before the given attempt using exponential backoff, as the base interval
+// multiplied by the multiplier raised to the attempt, plus the given jitter, in milliseconds precision. If the max
+// interval is positive, the interval is clamped to it. It is deterministic given the jitter, so it might be used to
+// preview the intervals of a configuration.
+func ComputeBackoff(base, max time.Duration, attempt int, multiplier float64, jitter time.Duration) time.Duration {
+
+	// The backoff saturates while still a float, since it would overflow time.Duration after enough attempts, as
+	// with unlimited retries.
+	backoff := maxDuration
+	if ms := float64(base.Milliseconds()) * math.Pow(multiplier, float64(attempt)); ms < float64(maxDuration/time.Millisecond) {
+		backoff = time.Duration(ms) * time.Millisecond
+	}
+	interval := saturatingAdd(backoff, jitter.Truncate(time.Millisecond))
+	if max > 0 && interval > max {
+		return max
+	}
+	return interval
+}
+
+// saturatingAdd adds the given delta to the given duration, saturating at maxDuration instead of overflowing.
+func saturatingAdd(d, delta time.Duration) time.Duration {
+	if delta > 0 && d > maxDuration-delta {
+		return maxDuration
+	}
+	return d + delta
+}
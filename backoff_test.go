@@ -0,0 +1,131 @@
+package hardy_test
+
+import (
+	"github.com/diegohordi/hardy"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeBackoff(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		base       time.Duration
+		max        time.Duration
+		attempt    int
+		multiplier float64
+		jitter     time.Duration
+		want       time.Duration
+	}{
+		{
+			name:       "should compute the exponential backoff",
+			base:       100 * time.Millisecond,
+			attempt:    3,
+			multiplier: 2,
+			want:       800 * time.Millisecond,
+		},
+		{
+			name:       "should add the jitter",
+			base:       100 * time.Millisecond,
+			attempt:    3,
+			multiplier: 2,
+			jitter:     250 * time.Millisecond,
+			want:       1050 * time.Millisecond,
+		},
+		{
+			name:       "should compute a constant backoff",
+			base:       100 * time.Millisecond,
+			attempt:    5,
+			multiplier: 1,
+			want:       100 * time.Millisecond,
+		},
+		{
+			name:       "should compute a gentle backoff",
+			base:       time.Second,
+			attempt:    2,
+			multiplier: 1.5,
+			want:       2250 * time.Millisecond,
+		},
+		{
+			name:       "should clamp to the max interval after many attempts",
+			base:       time.Second,
+			max:        30 * time.Second,
+			attempt:    64,
+			multiplier: 2,
+			jitter:     500 * time.Millisecond,
+			want:       30 * time.Second,
+		},
+		{
+			name:       "should saturate instead of overflowing without a max interval",
+			base:       time.Second,
+			attempt:    1000,
+			multiplier: 2,
+			jitter:     500 * time.Millisecond,
+			want:       time.Duration(math.MaxInt64),
+		},
+		{
+			name:       "should clamp to the max interval",
+			base:       time.Second,
+			max:        3 * time.Second,
+			attempt:    4,
+			multiplier: 2,
+			jitter:     500 * time.Millisecond,
+			want:       3 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := hardy.ComputeBackoff(tt.base, tt.max, tt.attempt, tt.multiplier, tt.jitter); got != tt.want {
+				t.Errorf("ComputeBackoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJitterFuncs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		jitterFunc hardy.JitterFunc
+		base       time.Duration
+		wantMin    time.Duration
+		wantMax    time.Duration
+	}{
+		{
+			name:       "should spread the interval across the whole backoff",
+			jitterFunc: hardy.FullJitter,
+			base:       time.Second,
+			wantMin:    time.Nanosecond,
+			wantMax:    time.Second,
+		},
+		{
+			name:       "should keep half of the backoff",
+			jitterFunc: hardy.EqualJitter,
+			base:       time.Second,
+			wantMin:    500*time.Millisecond + time.Nanosecond,
+			wantMax:    time.Second,
+		},
+		{
+			name:       "should not add full jitter to an empty backoff",
+			jitterFunc: hardy.FullJitter,
+		},
+		{
+			name:       "should not add equal jitter to an empty backoff",
+			jitterFunc: hardy.EqualJitter,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			for i := 0; i < 100; i++ {
+				if got := tt.base + tt.jitterFunc(tt.base); got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("interval = %v, want between %v and %v", got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
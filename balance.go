@@ -0,0 +1,130 @@
+package hardy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+const (
+	// endpointHealthHalfLife is the time it takes for the failure score of an endpoint to decay by half, so a
+	// recovered endpoint gradually re-enters the pool.
+	endpointHealthHalfLife = 30 * time.Second
+
+	// endpointPickPrecision is the precision of the random number used to pick an endpoint by its weight.
+	endpointPickPrecision = 1 << 53
+)
+
+// WeightedEndpoint defines an endpoint TryBalanced spreads the attempts across.
+type WeightedEndpoint struct {
+	// BaseURL is the base URL given to the RequestFactory.
+	BaseURL string
+
+	// Weight is the share of the attempts the endpoint should take, relative to the other endpoints.
+	Weight int
+}
+
+// endpointHealth holds the failure score of an endpoint, as of the time it was last updated.
+type endpointHealth struct {
+	score   float64
+	updated time.Time
+}
+
+// decayed gets the failure score decayed as of the given time.
+func (h endpointHealth) decayed(now time.Time) float64 {
+	elapsed := now.Sub(h.updated)
+	if elapsed <= 0 {
+		return h.score
+	}
+	return h.score * math.Pow(0.5, float64(elapsed)/float64(endpointHealthHalfLife))
+}
+
+// TryBalanced tries to perform the request built by the given RequestFactory as per configurations, picking the base
+// URL of each attempt among the endpoints given through WithEndpoints, with a probability proportional to their
+// weights. Each failure lowers the chance of the endpoint being picked, while each success restores it, as well as
+// the time, so the endpoints that recovered re-enter the pool. If some FallbackFunc is given, after max retries were
+// reached, it will be called. Besides the errors returned by Try, it might return ErrNoBaseURLFound if no endpoints
+// were given.
+func (c *Client) TryBalanced(ctx context.Context, reqFactory RequestFactory, readerFunc ReaderFunc, fallbackFunc FallbackFunc) error {
+	// The endpoints are picked as per a snapshot of the configuration, while their health is tracked by the client.
+	snapshot := c.snapshot()
+	endpoints, clock := snapshot.endpoints, snapshot.clock
+	if len(endpoints) == 0 {
+		return ErrNoBaseURLFound
+	}
+
+	// Builds the request for the given attempt, tracking the endpoints that failed.
+	var lastBase string
+	buildRequest := func(attempt int) (*http.Request, error) {
+		if attempt > 0 {
+			c.recordEndpointOutcome(lastBase, false, clock.Now())
+		}
+		random, err := snapshot.getRandom(endpointPickPrecision)
+		if err != nil {
+			return nil, err
+		}
+		lastBase = c.pickEndpoint(endpoints, clock.Now(), random)
+		req := reqFactory(lastBase)
+		if req == nil {
+			return nil, fmt.Errorf("no request was built for base URL %q", lastBase)
+		}
+		return req, nil
+	}
+
+	req, err := buildRequest(0)
+	if err != nil {
+		return newError(ErrUnexpected, withCause(err))
+	}
+
+	// The fallback is called only after the outcome of the last endpoint is recorded.
+	_, err = c.try(ctx, req, buildRequest, readerFunc, nil, false)
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return err
+	}
+	c.recordEndpointOutcome(lastBase, err == nil, clock.Now())
+	if err != nil && fallbackFunc != nil {
+		return snapshot.fallback(err, fallbackFunc)
+	}
+	return err
+}
+
+// pickEndpoint picks the base URL of one of the given endpoints with a probability proportional to its weight,
+// divided by one plus its failure score decayed as of the given time, through the given random number in
+// [0, endpointPickPrecision).
+func (c *Client) pickEndpoint(endpoints []WeightedEndpoint, now time.Time, random int64) string {
+	weights := make([]float64, len(endpoints))
+	var total float64
+	c.endpointHealthMu.Lock()
+	for i, endpoint := range endpoints {
+		weights[i] = float64(endpoint.Weight) / (1 + c.endpointHealth[endpoint.BaseURL].decayed(now))
+		total += weights[i]
+	}
+	c.endpointHealthMu.Unlock()
+
+	target := float64(random) / endpointPickPrecision * total
+	for i, endpoint := range endpoints {
+		if target < weights[i] {
+			return endpoint.BaseURL
+		}
+		target -= weights[i]
+	}
+	return endpoints[len(endpoints)-1].BaseURL
+}
+
+// recordEndpointOutcome records the outcome of an attempt against the given endpoint, increasing its failure score
+// at the given time if it failed or resetting it otherwise.
+func (c *Client) recordEndpointOutcome(base string, succeeded bool, now time.Time) {
+	c.endpointHealthMu.Lock()
+	defer c.endpointHealthMu.Unlock()
+	if succeeded {
+		delete(c.endpointHealth, base)
+		return
+	}
+	if c.endpointHealth == nil {
+		c.endpointHealth = make(map[string]endpointHealth)
+	}
+	c.endpointHealth[base] = endpointHealth{score: c.endpointHealth[base].decayed(now) + 1, updated: now}
+}
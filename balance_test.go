@@ -0,0 +1,189 @@
+package hardy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/diegohordi/hardy"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_TryBalanced(t *testing.T) {
+	t.Parallel()
+
+	var healthy int32 = 1
+	var primaryCalls, secondaryCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&secondaryCalls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer secondary.Close()
+
+	clock := &FakeClock{now: time.Now()}
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithClock(clock),
+		hardy.WithDeterministic(1),
+		hardy.WithMaxRetries(10),
+		hardy.WithEndpoints([]hardy.WeightedEndpoint{
+			{BaseURL: primary.URL, Weight: 3},
+			{BaseURL: secondary.URL, Weight: 1},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqFactory := func(base string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, base+"/resource", nil)
+		return req
+	}
+	readerFunc := func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s", response.Status)
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name            string
+		healthy         bool
+		elapsed         time.Duration
+		calls           int
+		minPrimaryShare float64
+		maxPrimaryShare float64
+	}{
+		{
+			name:            "should spread the attempts proportionally to the weights",
+			healthy:         true,
+			calls:           400,
+			minPrimaryShare: 0.65,
+			maxPrimaryShare: 0.85,
+		},
+		{
+			name:            "should prefer the endpoints without recent failures",
+			healthy:         false,
+			calls:           400,
+			minPrimaryShare: 0,
+			maxPrimaryShare: 0.2,
+		},
+		{
+			name:            "should let the recovered endpoints re-enter the pool as the failures decay",
+			healthy:         true,
+			elapsed:         time.Hour,
+			calls:           400,
+			minPrimaryShare: 0.65,
+			maxPrimaryShare: 0.85,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.healthy {
+				atomic.StoreInt32(&healthy, 1)
+			} else {
+				atomic.StoreInt32(&healthy, 0)
+			}
+			clock.mu.Lock()
+			clock.now = clock.now.Add(tt.elapsed)
+			clock.mu.Unlock()
+			atomic.StoreInt32(&primaryCalls, 0)
+			atomic.StoreInt32(&secondaryCalls, 0)
+			for i := 0; i < tt.calls; i++ {
+				if err := client.TryBalanced(context.TODO(), reqFactory, readerFunc, nil); err != nil {
+					t.Fatalf("TryBalanced() error = %v", err)
+				}
+			}
+			primaryCalls, secondaryCalls := atomic.LoadInt32(&primaryCalls), atomic.LoadInt32(&secondaryCalls)
+			share := float64(primaryCalls) / float64(primaryCalls+secondaryCalls)
+			if share < tt.minPrimaryShare || share > tt.maxPrimaryShare {
+				t.Errorf("TryBalanced() primary share = %.2f, want between %.2f and %.2f", share, tt.minPrimaryShare, tt.maxPrimaryShare)
+			}
+		})
+	}
+}
+
+func TestClient_TryBalanced_Concurrently(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/failing/resource" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(10),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithEndpoints([]hardy.WeightedEndpoint{
+			{BaseURL: server.URL + "/failing", Weight: 1},
+			{BaseURL: server.URL, Weight: 1},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqFactory := func(base string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, base+"/resource", nil)
+		return req
+	}
+	readerFunc := func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s", response.Status)
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- client.TryBalanced(context.TODO(), reqFactory, readerFunc, nil)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("TryBalanced() error = %v", err)
+		}
+	}
+}
+
+func TestClient_TryBalanced_NoEndpoints(t *testing.T) {
+	t.Parallel()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.TryBalanced(context.TODO(), func(base string) *http.Request { return nil }, func(response *http.Response) error { return nil }, nil)
+	if !errors.Is(err, hardy.ErrNoBaseURLFound) {
+		t.Errorf("TryBalanced() error = %v, errWant %v", err, hardy.ErrNoBaseURLFound)
+	}
+}
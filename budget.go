@@ -0,0 +1,125 @@
+package hardy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryBudget is a token bucket shared across clients, guarding against retry amplification during widespread
+// outages. Each retry withdraws a token, while each successful request deposits a fraction of one, so retries
+// are refused once the budget is exhausted until successful requests refill it. It is safe for concurrent use.
+type RetryBudget struct {
+	mu sync.Mutex
+
+	// tokens is the number of tokens currently available.
+	tokens float64
+
+	// maxTokens is the maximum number of tokens the budget can hold.
+	maxTokens float64
+
+	// refillRatio is the number of tokens deposited on each successful request.
+	refillRatio float64
+}
+
+// NewRetryBudget creates a new full RetryBudget holding up to maxTokens retries, refilled by refillRatio tokens on
+// each successful request. A refillRatio of 0.1, for instance, allows one retry for each ten successful requests.
+func NewRetryBudget(maxTokens, refillRatio float64) (*RetryBudget, error) {
+	if maxTokens <= 0 {
+		return nil, newError(ErrInvalidClientConfiguration, withCause(fmt.Errorf("max tokens must be greater than zero: %v", maxTokens)))
+	}
+	if refillRatio <= 0 {
+		return nil, newError(ErrInvalidClientConfiguration, withCause(fmt.Errorf("refill ratio must be greater than zero: %v", refillRatio)))
+	}
+	return &RetryBudget{
+		tokens:      maxTokens,
+		maxTokens:   maxTokens,
+		refillRatio: refillRatio,
+	}, nil
+}
+
+// Tokens returns the number of tokens currently available.
+func (b *RetryBudget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+// withdraw debits a token for a retry, returning false if the budget is exhausted.
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// deposit refills the budget after a successful request.
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.refillRatio
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
+	}
+}
+
+// contextBudgetKey is the context key of the attempt budget attached through ContextWithBudget.
+type contextBudgetKey struct{}
+
+// contextBudget is the attempt budget shared by the Try calls made with the same context, as all the downstream
+// calls of a single inbound request. It is safe for concurrent use.
+type contextBudget struct {
+
+	// remaining is the number of attempts still allowed, if bounded.
+	remaining int64
+
+	// bounded determines if the attempts are bounded.
+	bounded bool
+
+	// parent is the budget attached to the parent context, if any, which is debited as well.
+	parent *contextBudget
+}
+
+// ContextWithBudget returns a copy of the given context carrying an attempt budget shared by all the Try calls made
+// with it, or with the contexts derived from it, as when a single inbound request fans out to several downstream
+// calls, sequentially or in parallel. Each attempt debits the budget, so once up to maxAttempts attempts were
+// performed, the next retries and the subsequent calls fail fast with ErrContextBudgetExhausted. The given deadline
+// bounds all the calls as well, as context.WithDeadline does. A non-positive maxAttempts doesn't bound the attempts,
+// while a zero deadline doesn't bound the time. Any budget attached to the given context is still debited.
+//
+// Canceling the returned context releases its resources, so the returned context.CancelFunc should be called as
+// soon as the calls are over.
+func ContextWithBudget(ctx context.Context, maxAttempts int, deadline time.Time) (context.Context, context.CancelFunc) {
+	budget := &contextBudget{
+		remaining: int64(maxAttempts),
+		bounded:   maxAttempts > 0,
+		parent:    contextBudgetFrom(ctx),
+	}
+	ctx = context.WithValue(ctx, contextBudgetKey{}, budget)
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// contextBudgetFrom gets the attempt budget attached to the given context, if any.
+func contextBudgetFrom(ctx context.Context) *contextBudget {
+	budget, _ := ctx.Value(contextBudgetKey{}).(*contextBudget)
+	return budget
+}
+
+// debit debits an attempt from the budget and from the ones of the parent contexts, returning false if any of them
+// is exhausted.
+func (b *contextBudget) debit() bool {
+	for budget := b; budget != nil; budget = budget.parent {
+		if budget.bounded && atomic.AddInt64(&budget.remaining, -1) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,273 @@
+package hardy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/diegohordi/hardy"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewRetryBudget(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		maxTokens   float64
+		refillRatio float64
+		wantErr     bool
+	}{
+		{
+			name:        "should create the retry budget",
+			maxTokens:   10,
+			refillRatio: 0.1,
+		},
+		{
+			name:        "should fail due to invalid max tokens",
+			maxTokens:   0,
+			refillRatio: 0.1,
+			wantErr:     true,
+		},
+		{
+			name:        "should fail due to invalid refill ratio",
+			maxTokens:   10,
+			refillRatio: 0,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			budget, err := hardy.NewRetryBudget(tt.maxTokens, tt.refillRatio)
+			if err != nil != tt.wantErr {
+				t.Fatalf("NewRetryBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, hardy.ErrInvalidClientConfiguration) {
+					t.Errorf("NewRetryBudget() error = %v, errWant %v", err, hardy.ErrInvalidClientConfiguration)
+				}
+				return
+			}
+			if budget.Tokens() != tt.maxTokens {
+				t.Errorf("NewRetryBudget() tokens = %v, want %v", budget.Tokens(), tt.maxTokens)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	budget, err := hardy.NewRetryBudget(2, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var failing int32 = 1
+	var calls int32
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&calls, 1)
+			resp := httptest.NewRecorder()
+			if atomic.LoadInt32(&failing) == 1 {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+
+	// Two clients sharing the same budget.
+	clients := make([]*hardy.Client, 2)
+	for i := range clients {
+		clients[i], err = hardy.NewClient(
+			hardy.WithHttpClient(httpClient),
+			hardy.WithDebugDisabled(),
+			hardy.WithMaxRetries(10),
+			hardy.WithWaitInterval(1*time.Millisecond),
+			hardy.WithMaxInterval(1*time.Millisecond),
+			hardy.WithRetryBudget(budget),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	readerFunc := func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s", response.Status)
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(client *hardy.Client) {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err := client.Try(context.TODO(), req, readerFunc, nil)
+			if !errors.Is(err, hardy.ErrRetryBudgetExhausted) {
+				t.Errorf("Try() error = %v, errWant %v", err, hardy.ErrRetryBudgetExhausted)
+			}
+		}(clients[i])
+	}
+	wg.Wait()
+
+	// Two first attempts plus the two retries allowed by the budget.
+	if got := atomic.LoadInt32(&calls); got != 4 {
+		t.Errorf("Try() calls = %d, want %d", got, 4)
+	}
+	if budget.Tokens() != 0 {
+		t.Errorf("Tokens() = %v, want %v", budget.Tokens(), 0)
+	}
+
+	// Successful requests refill the budget.
+	atomic.StoreInt32(&failing, 0)
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+		if err := clients[0].Try(context.TODO(), req, readerFunc, nil); err != nil {
+			t.Fatalf("Try() error = %v", err)
+		}
+	}
+	if budget.Tokens() != 1 {
+		t.Errorf("Tokens() = %v, want %v", budget.Tokens(), 1)
+	}
+}
+
+func TestContextWithBudget(t *testing.T) {
+	t.Parallel()
+
+	newClient := func(t *testing.T, calls *int32) *hardy.Client {
+		httpClient := &http.Client{
+			Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(calls, 1)
+				resp := httptest.NewRecorder()
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}),
+		}
+		client, err := hardy.NewClient(
+			hardy.WithHttpClient(httpClient),
+			hardy.WithDebugDisabled(),
+			hardy.WithMaxRetries(3),
+			hardy.WithWaitInterval(1*time.Millisecond),
+			hardy.WithMaxInterval(1*time.Millisecond),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	readerFunc := func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}
+
+	t.Run("should share the attempts across sequential calls", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		client := newClient(t, &calls)
+		ctx, cancel := hardy.ContextWithBudget(context.TODO(), 4, time.Time{})
+		defer cancel()
+
+		wantErrs := []error{hardy.ErrMaxRetriesReached, hardy.ErrContextBudgetExhausted, hardy.ErrContextBudgetExhausted}
+		wantCalls := []int32{3, 4, 4}
+		for i := range wantErrs {
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err := client.Try(ctx, req, readerFunc, nil)
+			if !errors.Is(err, wantErrs[i]) {
+				t.Errorf("Try() #%d error = %v, errWant %v", i+1, err, wantErrs[i])
+			}
+			if got := atomic.LoadInt32(&calls); got != wantCalls[i] {
+				t.Errorf("Try() #%d calls = %d, want %d", i+1, got, wantCalls[i])
+			}
+		}
+	})
+
+	t.Run("should share the attempts across parallel calls", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		client := newClient(t, &calls)
+		ctx, cancel := hardy.ContextWithBudget(context.TODO(), 10, time.Time{})
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+				_ = client.Try(ctx, req, readerFunc, nil)
+			}()
+		}
+		wg.Wait()
+		if got := atomic.LoadInt32(&calls); got != 10 {
+			t.Errorf("Try() calls = %d, want %d", got, 10)
+		}
+	})
+
+	t.Run("should debit the budget of the parent context", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		client := newClient(t, &calls)
+		parent, cancelParent := hardy.ContextWithBudget(context.TODO(), 2, time.Time{})
+		defer cancelParent()
+		ctx, cancel := hardy.ContextWithBudget(parent, 0, time.Time{})
+		defer cancel()
+
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+		err := client.Try(ctx, req, readerFunc, nil)
+		if !errors.Is(err, hardy.ErrContextBudgetExhausted) {
+			t.Errorf("Try() error = %v, errWant %v", err, hardy.ErrContextBudgetExhausted)
+		}
+		if got := atomic.LoadInt32(&calls); got != 2 {
+			t.Errorf("Try() calls = %d, want %d", got, 2)
+		}
+	})
+
+	t.Run("should fail fast once the deadline was reached", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		client := newClient(t, &calls)
+		ctx, cancel := hardy.ContextWithBudget(context.TODO(), 0, time.Now().Add(-time.Second))
+		defer cancel()
+
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+		err := client.Try(ctx, req, readerFunc, nil)
+		if !errors.Is(err, hardy.ErrTimeout) {
+			t.Errorf("Try() error = %v, errWant %v", err, hardy.ErrTimeout)
+		}
+	})
+
+	t.Run("should call the fallback once the budget is spent", func(t *testing.T) {
+		t.Parallel()
+		var calls int32
+		client := newClient(t, &calls)
+		ctx, cancel := hardy.ContextWithBudget(context.TODO(), 1, time.Time{})
+		defer cancel()
+
+		var fallbacks int32
+		fallbackFunc := func() error {
+			atomic.AddInt32(&fallbacks, 1)
+			return nil
+		}
+		for i := 0; i < 2; i++ {
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			if err := client.Try(ctx, req, readerFunc, fallbackFunc); err != nil {
+				t.Errorf("Try() #%d error = %v, want nil", i+1, err)
+			}
+		}
+		if got := atomic.LoadInt32(&fallbacks); got != 2 {
+			t.Errorf("Try() fallbacks = %d, want %d", got, 2)
+		}
+		if got := atomic.LoadInt32(&calls); got != 1 {
+			t.Errorf("Try() calls = %d, want %d", got, 1)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package hardy
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+
+	// contentEncodingHeader is the header telling the encoding applied to the request body.
+	contentEncodingHeader = "Content-Encoding"
+
+	// gzipEncoding is the Content-Encoding of the bodies compressed through gzip.
+	gzipEncoding = "gzip"
+)
+
+// compressedContentTypes holds the media types of the bodies already compressed, which aren't worth compressing again.
+var compressedContentTypes = map[string]struct{}{
+	"application/gzip":             {},
+	"application/x-gzip":           {},
+	"application/zip":              {},
+	"application/zstd":             {},
+	"application/x-7z-compressed":  {},
+	"application/x-bzip2":          {},
+	"application/x-xz":             {},
+	"application/x-rar-compressed": {},
+}
+
+// compressBody compresses the replayable body of the given request through gzip if it is larger than the min size
+// given by WithRequestBodyGzip, replacing the body and its headers, unless it was already encoded, as told by its
+// Content-Encoding or Content-Type headers. The compressed body is buffered in memory, unless it is larger than the
+// body spill threshold, if given, in which case it is streamed to a temporary file tracked by the given spillFiles.
+func (c *Client) compressBody(ctx context.Context, req *http.Request, spilled *spillFiles) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return nil
+	}
+	if req.Header.Get(contentEncodingHeader) != "" || isCompressedContentType(req.Header.Get(contentTypeHeader)) {
+		return nil
+	}
+	if req.ContentLength > 0 && req.ContentLength <= c.gzipMinSize {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("error while compressing request body: %w", err)
+	}
+	defer func() {
+		if closeErr := body.Close(); closeErr != nil && c.isDebugEnabled(ctx) {
+			c.debugPrintln(fmt.Errorf("error while closing request body: %w", closeErr))
+		}
+	}()
+
+	compressed := &spillWriter{files: spilled, threshold: c.bodySpillThreshold}
+	writer := gzip.NewWriter(compressed)
+	n, err := io.Copy(writer, body)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("error while compressing request body: %w", err)
+	}
+	if n <= c.gzipMinSize {
+		return nil
+	}
+
+	if err := compressed.setBody(req); err != nil {
+		return fmt.Errorf("error while compressing request body: %w", err)
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Set(contentEncodingHeader, gzipEncoding)
+	return nil
+}
+
+// isCompressedContentType checks if the given Content-Type tells about an already compressed body, as archives,
+// images, audios and videos.
+func isCompressedContentType(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if _, ok := compressedContentTypes[mediaType]; ok {
+		return true
+	}
+	if mediaType == "image/svg+xml" {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "image/") || strings.HasPrefix(mediaType, "audio/") || strings.HasPrefix(mediaType, "video/")
+}
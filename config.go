@@ -0,0 +1,48 @@
+package hardy
+
+import (
+	"time"
+)
+
+// ClientConfig holds a read-only snapshot of the effective configuration of a Client.
+type ClientConfig struct {
+
+	// Name is the name the client was labeled with, or empty if none was given.
+	Name string
+
+	// MaxRetries is the maximum number of attempts performed.
+	MaxRetries int
+
+	// WaitInterval is the base duration between each retry.
+	WaitInterval time.Duration
+
+	// MaxInterval is the max interval between each retry.
+	MaxInterval time.Duration
+
+	// Multiplier is the multiplier used to calculate the backoff interval.
+	Multiplier float64
+
+	// Debug tells if the debug mode is enabled.
+	Debug bool
+
+	// UserAgent is the User-Agent header added to the requests, or empty if none is added.
+	UserAgent string
+}
+
+// Config returns a snapshot of the effective configuration of the client, useful for diagnostics.
+func (c *Client) Config() ClientConfig {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	cfg := ClientConfig{
+		Name:         c.name,
+		MaxRetries:   c.maxRetries,
+		WaitInterval: c.waitInterval,
+		MaxInterval:  c.maxInterval,
+		Multiplier:   c.multiplier,
+		Debug:        c.debug,
+	}
+	if c.withUserAgentHeader {
+		cfg.UserAgent = c.userAgent
+	}
+	return cfg
+}
@@ -0,0 +1,348 @@
+package hardy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/diegohordi/hardy"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_Config(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		options []hardy.Option
+		want    hardy.ClientConfig
+	}{
+		{
+			name: "should return the default configuration",
+			want: hardy.ClientConfig{
+				MaxRetries:   hardy.DefaultMaxRetries,
+				WaitInterval: hardy.DefaultWaitIntervalMilliseconds * time.Millisecond,
+				MaxInterval:  hardy.DefaultMaxIntervalInMilliseconds * time.Millisecond,
+				Multiplier:   hardy.DefaultBackoffMultiplier,
+				Debug:        true,
+				UserAgent:    fmt.Sprintf("go-hardy-http-client/%s (%s)", hardy.ClientVersion, runtime.Version()),
+			},
+		},
+		{
+			name: "should return the given configuration",
+			options: []hardy.Option{
+				hardy.WithMaxRetries(5),
+				hardy.WithWaitInterval(10 * time.Millisecond),
+				hardy.WithMaxInterval(time.Second),
+				hardy.WithBackoffMultiplier(3),
+				hardy.WithDebugDisabled(),
+				hardy.WithNoUserAgentHeader(),
+				hardy.WithName("payments"),
+			},
+			want: hardy.ClientConfig{
+				Name:         "payments",
+				MaxRetries:   5,
+				WaitInterval: 10 * time.Millisecond,
+				MaxInterval:  time.Second,
+				Multiplier:   3,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := hardy.NewClient(tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := client.Config(); got != tt.want {
+				t.Errorf("Config() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Reconfigure(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		options []hardy.Option
+		wantErr bool
+		want    func(cfg hardy.ClientConfig) hardy.ClientConfig
+	}{
+		{
+			name: "should reconfigure the client",
+			options: []hardy.Option{
+				hardy.WithMaxRetries(7),
+				hardy.WithClientIdentity("myapp", "2.0"),
+			},
+			want: func(cfg hardy.ClientConfig) hardy.ClientConfig {
+				cfg.MaxRetries = 7
+				cfg.UserAgent = fmt.Sprintf("myapp/2.0 (%s)", runtime.Version())
+				return cfg
+			},
+		},
+		{
+			name: "should keep the User-Agent header built along with the reconfigured identity",
+			options: []hardy.Option{
+				hardy.WithUserAgentSuffix("worker/1.0"),
+				hardy.WithMaxRetries(5),
+			},
+			want: func(cfg hardy.ClientConfig) hardy.ClientConfig {
+				cfg.MaxRetries = 5
+				cfg.UserAgent = cfg.UserAgent + " worker/1.0"
+				return cfg
+			},
+		},
+		{
+			name: "should fail due to a transport option",
+			options: []hardy.Option{
+				hardy.WithMaxRetries(7),
+				hardy.WithIdleConnTimeout(time.Second),
+			},
+			wantErr: true,
+		},
+		{
+			name: "should fail due to a cookie jar",
+			options: []hardy.Option{
+				hardy.WithCookieJar(&cookiejar.Jar{}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "should fail due to the max redirects",
+			options: []hardy.Option{
+				hardy.WithMaxRedirects(3),
+			},
+			wantErr: true,
+		},
+		{
+			name: "should fail due to the max concurrency",
+			options: []hardy.Option{
+				hardy.WithMaxConcurrency(2),
+			},
+			wantErr: true,
+		},
+		{
+			name: "should fail due to an invalid option",
+			options: []hardy.Option{
+				hardy.WithMaxRetries(7),
+				hardy.WithBackoffMultiplier(0.5),
+			},
+			wantErr: true,
+		},
+		{
+			name: "should fail due to an option invalid along with the configured ones",
+			options: []hardy.Option{
+				hardy.WithMinInterval(10 * time.Second),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := hardy.NewClient(hardy.WithDebugDisabled(), hardy.WithMaxInterval(5*time.Second))
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := client.Config()
+			err = client.Reconfigure(tt.options...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reconfigure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			want := before
+			if tt.wantErr {
+				if !errors.Is(err, hardy.ErrInvalidClientConfiguration) {
+					t.Errorf("Reconfigure() error = %v, errWant %v", err, hardy.ErrInvalidClientConfiguration)
+				}
+			} else {
+				want = tt.want(before)
+			}
+			if got := client.Config(); got != want {
+				t.Errorf("Config() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestClient_Reconfigure_WhileTrying(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			_ = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+		}()
+		go func(n int) {
+			defer wg.Done()
+			if err := client.Reconfigure(hardy.WithMaxRetries(n + 1)); err != nil {
+				t.Errorf("Reconfigure() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestClient_Reconfigure_WhileRedirecting(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRedirects(3),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/redirect", nil)
+			if err := client.Try(context.TODO(), req, func(response *http.Response) error {
+				return nil
+			}, nil); err != nil {
+				t.Errorf("Try() error = %v", err)
+			}
+		}()
+		go func(n int) {
+			defer wg.Done()
+			if err := client.Reconfigure(hardy.WithMaxRetries(n + 1)); err != nil {
+				t.Errorf("Reconfigure() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestClient_Reconfigure_DuringCall(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stream" {
+			_, _ = fmt.Fprint(w, "data: 1\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithName("original"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each step must be over well before the timeout, unless it is blocked by a call in flight.
+	within := func(t *testing.T, step string, f func()) {
+		t.Helper()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			f()
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s blocked by the call in flight", step)
+		}
+	}
+
+	t.Run("should reconfigure the client from within the ReaderFunc", func(t *testing.T) {
+		within(t, "Try()", func() {
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			err := client.Try(context.TODO(), req, func(response *http.Response) error {
+				if err := client.Reconfigure(hardy.WithName("reloaded")); err != nil {
+					return hardy.Permanent(err)
+				}
+				if got := client.Name(); got != "reloaded" {
+					return hardy.Permanent(fmt.Errorf("Name() = %q, want %q", got, "reloaded"))
+				}
+				nested, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+				return client.Try(context.TODO(), nested, func(response *http.Response) error {
+					return nil
+				}, nil)
+			}, nil)
+			if err != nil {
+				t.Errorf("Try() error = %v", err)
+			}
+		})
+	})
+
+	t.Run("should perform other calls while a stream is open", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		streaming := make(chan struct{})
+		streamDone := make(chan error, 1)
+		go func() {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/stream", nil)
+			var once sync.Once
+			streamDone <- client.TryStream(ctx, req, func(line []byte) error {
+				once.Do(func() { close(streaming) })
+				return nil
+			})
+		}()
+		<-streaming
+
+		within(t, "Reconfigure()", func() {
+			if err := client.Reconfigure(hardy.WithMaxRetries(5)); err != nil {
+				t.Errorf("Reconfigure() error = %v", err)
+			}
+		})
+		within(t, "Try()", func() {
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err := client.Try(context.TODO(), req, func(response *http.Response) error {
+				return nil
+			}, nil); err != nil {
+				t.Errorf("Try() error = %v", err)
+			}
+		})
+		cancel()
+		if err := <-streamDone; !errors.Is(err, context.Canceled) {
+			t.Errorf("TryStream() error = %v, errWant %v", err, context.Canceled)
+		}
+	})
+}
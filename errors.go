@@ -1,6 +1,12 @@
 package hardy
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+)
 
 // ErrorCode is the type of well-known error codes.
 type ErrorCode string
@@ -16,12 +22,64 @@ const (
 	// ErrNoHTTPClientFound is the error returned when no HTTP Client was given.
 	ErrNoHTTPClientFound ErrorCode = "no_http_client_found_error"
 
+	// ErrNoClockFound is the error returned when no Clock was given.
+	ErrNoClockFound ErrorCode = "no_clock_found_error"
+
+	// ErrHTTPClientNotConfigurable is the error returned when some transport configuration was given along with
+	// a custom HTTP Client, which shouldn't be mutated.
+	ErrHTTPClientNotConfigurable ErrorCode = "http_client_not_configurable_error"
+
 	// ErrNoReaderFuncFound is the error returned when no ReaderFunc was given.
 	ErrNoReaderFuncFound ErrorCode = "no_reader_func_found_error"
 
+	// ErrNoBaseURLFound is the error returned when no base URL was given.
+	ErrNoBaseURLFound ErrorCode = "no_base_url_found_error"
+
 	// ErrMaxRetriesReached is the error returned when the max allowed retries were reached.
 	ErrMaxRetriesReached ErrorCode = "max_retries_reached_error"
 
+	// ErrMaxElapsedTimeReached is the error returned when the max elapsed time doesn't allow a new attempt.
+	ErrMaxElapsedTimeReached ErrorCode = "max_elapsed_time_reached_error"
+
+	// ErrRetryBudgetExhausted is the error returned when the retry budget doesn't allow a new attempt.
+	ErrRetryBudgetExhausted ErrorCode = "retry_budget_exhausted_error"
+
+	// ErrContextBudgetExhausted is the error returned when the attempt budget attached to the context through
+	// ContextWithBudget doesn't allow a new attempt.
+	ErrContextBudgetExhausted ErrorCode = "context_budget_exhausted_error"
+
+	// ErrBodyNotReplayable is the error returned when the attempt failed and the request body can't be replayed.
+	ErrBodyNotReplayable ErrorCode = "body_not_replayable_error"
+
+	// ErrImmediateFallback is the error returned when the response status code requires the fallback, but no
+	// FallbackFunc was given.
+	ErrImmediateFallback ErrorCode = "immediate_fallback_error"
+
+	// ErrExpectationFailed is the error returned when the server rejected the Expect: 100-continue header sent
+	// along with the request, with a 417 HTTP status code.
+	ErrExpectationFailed ErrorCode = "expectation_failed_error"
+
+	// ErrResponseHeadersTooLarge is the error returned when the response headers exceeded the max size given by
+	// WithMaxResponseHeaderBytes.
+	ErrResponseHeadersTooLarge ErrorCode = "response_headers_too_large_error"
+
+	// ErrTooManyRedirects is the error returned when an attempt exceeded the max redirects given by WithMaxRedirects.
+	ErrTooManyRedirects ErrorCode = "too_many_redirects_error"
+
+	// ErrPreflightFailed is the error returned when the CORS preflight performed by TryPreflight failed.
+	ErrPreflightFailed ErrorCode = "preflight_failed_error"
+
+	// ErrTimeout is the error returned when the request context deadline was exceeded, wrapping
+	// context.DeadlineExceeded.
+	ErrTimeout ErrorCode = "timeout_error"
+
+	// ErrCanceled is the error returned when the request context was canceled, wrapping context.Canceled.
+	ErrCanceled ErrorCode = "canceled_error"
+
+	// ErrRetryRequest is the error that should be returned by the BeforeRequestFunc when a new attempt should be
+	// performed, as when a token refresh transiently failed.
+	ErrRetryRequest ErrorCode = "retry_request_error"
+
 	// ErrUnexpected is the error returned when no one of the previous errors match.
 	ErrUnexpected ErrorCode = "unexpected_error"
 )
@@ -53,9 +111,45 @@ func (e Error) Error() string {
 	return string(ret)
 }
 
-// Is checks if the given target error equals to this error code
+// MarshalJSON returns the JSON representation of the given error, including its cause, which is nested as an
+// object if it is also an Error.
+func (e Error) MarshalJSON() ([]byte, error) {
+	type errorAlias Error
+	out := struct {
+		errorAlias
+		Cause any `json:"cause,omitempty"`
+	}{
+		errorAlias: errorAlias(e),
+	}
+	if e.cause != nil {
+		if causeErr, ok := e.cause.(Error); ok {
+			out.Cause = causeErr
+		} else {
+			out.Cause = e.cause.Error()
+		}
+	}
+	return json.Marshal(out)
+}
+
+// Is checks if the given target error equals to this error code, which might be given as an ErrorCode or as another
+// Error, so an Error matches any other one with the same error code regardless of its message and cause.
 func (e Error) Is(tgt error) bool {
-	return e.ErrorCode == tgt
+	switch target := tgt.(type) {
+	case ErrorCode:
+		return e.ErrorCode == target
+	case Error:
+		return e.ErrorCode == target.ErrorCode
+	case *Error:
+		return target != nil && e.ErrorCode == target.ErrorCode
+	default:
+		return false
+	}
+}
+
+// Unwrap returns the error that caused this error, if any, so the standard errors, as context.DeadlineExceeded, might
+// be matched through errors.Is.
+func (e Error) Unwrap() error {
+	return e.cause
 }
 
 // errorOption defines an error builder option
@@ -81,3 +175,75 @@ func withCause(cause error) errorOption {
 		}
 	}
 }
+
+// newContextError builds the Error for the given context error, as ErrTimeout for context.DeadlineExceeded and
+// ErrCanceled for context.Canceled, wrapping it.
+func newContextError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return newError(ErrTimeout, withCause(err))
+	case errors.Is(err, context.Canceled):
+		return newError(ErrCanceled, withCause(err))
+	default:
+		return err
+	}
+}
+
+// permanentError wraps an error that shouldn't allow new attempts.
+type permanentError struct {
+	err error
+}
+
+// Error returns the string representation of the wrapped error.
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps the given error signaling that it is not worth a new attempt. When returned from a ReaderFunc,
+// the request will not be retried and the wrapped error will be returned instead.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// asPermanent checks if the given error was wrapped by Permanent, returning the wrapped error.
+func asPermanent(err error) (error, bool) {
+	var permanentErr permanentError
+	if errors.As(err, &permanentErr) {
+		return permanentErr.err, true
+	}
+	return nil, false
+}
+
+// IsConnectivityError checks if the given error was caused by a transport connection error, as a refused or reset
+// connection or a network timeout, meaning the server couldn't be reached, unlike the errors caused by its responses,
+// as a 503 HTTP status code. It is mostly useful with the ErrMaxRetriesReached returned after the attempts kept
+// failing, which wraps the last transport error, to tell a backend down from a backend erroring. The timeouts of the
+// dials and of the response headers are connectivity errors, while the requests stopped by their contexts being done,
+// as by the caller cancellation, are not.
+func IsConnectivityError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// The dial and response headers timeouts match context.DeadlineExceeded as well, so only the requests failed with
+	// the error of a context itself are told apart.
+	var urlErr *url.Error
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &urlErr):
+		if urlErr.Err == context.DeadlineExceeded {
+			return false
+		}
+	case !errors.As(err, &opErr):
+		return false
+	}
+	return isConnectionError(err)
+}
@@ -0,0 +1,290 @@
+package hardy_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/diegohordi/hardy"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestError_Error(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return errors.New("upstream unavailable")
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(err.Error()), &got); err != nil {
+		t.Fatalf("Error() is not a valid JSON: %v", err)
+	}
+	if got["error_code"] != string(hardy.ErrMaxRetriesReached) {
+		t.Errorf("Error() error_code = %v, want %v", got["error_code"], hardy.ErrMaxRetriesReached)
+	}
+	if got["cause"] != "upstream unavailable" {
+		t.Errorf("Error() cause = %v, want %v", got["cause"], "upstream unavailable")
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	tryErr := client.Try(context.TODO(), req, func(response *http.Response) error {
+		return fmt.Errorf("reading: %w", hardy.Error{ErrorCode: hardy.ErrRetryBudgetExhausted, Message: "downstream"})
+	}, nil)
+
+	tests := []struct {
+		name      string
+		err       error
+		target    error
+		wantMatch bool
+	}{
+		{
+			name:      "should match an error code through multiple wrapping layers",
+			err:       fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", hardy.Error{ErrorCode: hardy.ErrUnexpected})),
+			target:    hardy.ErrUnexpected,
+			wantMatch: true,
+		},
+		{
+			name:      "should match an error with the same error code through multiple wrapping layers",
+			err:       fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", hardy.Error{ErrorCode: hardy.ErrUnexpected, Message: "inner"})),
+			target:    hardy.Error{ErrorCode: hardy.ErrUnexpected, Message: "other"},
+			wantMatch: true,
+		},
+		{
+			name:      "should match a pointer to an error with the same error code",
+			err:       fmt.Errorf("outer: %w", hardy.Error{ErrorCode: hardy.ErrUnexpected}),
+			target:    &hardy.Error{ErrorCode: hardy.ErrUnexpected},
+			wantMatch: true,
+		},
+		{
+			name:   "should not match an error with another error code",
+			err:    fmt.Errorf("outer: %w", hardy.Error{ErrorCode: hardy.ErrUnexpected}),
+			target: hardy.Error{ErrorCode: hardy.ErrTimeout},
+		},
+		{
+			name:   "should not match a nil pointer to an error",
+			err:    hardy.Error{ErrorCode: hardy.ErrUnexpected},
+			target: (*hardy.Error)(nil),
+		},
+		{
+			name:      "should match the error code of the returned error wrapped again",
+			err:       fmt.Errorf("calling: %w", tryErr),
+			target:    hardy.Error{ErrorCode: hardy.ErrMaxRetriesReached},
+			wantMatch: true,
+		},
+		{
+			name:      "should match the error code of the nested cause through the returned error",
+			err:       fmt.Errorf("calling: %w", tryErr),
+			target:    hardy.ErrRetryBudgetExhausted,
+			wantMatch: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := errors.Is(tt.err, tt.target); got != tt.wantMatch {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestError_ContextErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		errWant []error
+	}{
+		{
+			name: "should fail due to the context deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.TODO(), 10*time.Millisecond)
+			},
+			errWant: []error{hardy.ErrTimeout, context.DeadlineExceeded},
+		},
+		{
+			name: "should fail due to the context cancellation",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.TODO())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			errWant: []error{hardy.ErrCanceled, context.Canceled},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// The request hangs until the test is over, so only the context might stop it.
+			release := make(chan struct{})
+			defer close(release)
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					<-release
+					return nil, errors.New("released")
+				}),
+			}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(ctx, req, func(response *http.Response) error {
+				return errors.New(response.Status)
+			}, nil)
+			for _, errWant := range tt.errWant {
+				if !errors.Is(err, errWant) {
+					t.Errorf("Try() error = %v, errWant %v", err, errWant)
+				}
+			}
+		})
+	}
+}
+
+func TestIsConnectivityError(t *testing.T) {
+	t.Parallel()
+
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable.Close()
+	erroring := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer erroring.Close()
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hanging.Close()
+	defer close(release)
+
+	tests := []struct {
+		name             string
+		options          []hardy.Option
+		endpoint         string
+		wantConnectivity bool
+	}{
+		{
+			name:             "should report a connectivity error when the server kept unreachable",
+			endpoint:         unreachable.URL,
+			wantConnectivity: true,
+		},
+		{
+			name:             "should report a connectivity error when the server kept not responding in time",
+			options:          []hardy.Option{hardy.WithResponseHeaderTimeout(20 * time.Millisecond)},
+			endpoint:         hanging.URL,
+			wantConnectivity: true,
+		},
+		{
+			name:     "should not report a connectivity error when the server kept erroring",
+			endpoint: erroring.URL,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]hardy.Option{
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(3),
+				hardy.WithWaitInterval(1 * time.Millisecond),
+				hardy.WithMaxInterval(1 * time.Millisecond),
+			}, tt.options...)
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, _ := http.NewRequest(http.MethodGet, tt.endpoint, nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return errors.New(response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+			}
+			if got := hardy.IsConnectivityError(err); got != tt.wantConnectivity {
+				t.Errorf("IsConnectivityError() = %v, want %v", got, tt.wantConnectivity)
+			}
+			if got := strings.Contains(err.Error(), "server unreachable after 3 attempts"); got != tt.wantConnectivity {
+				t.Errorf("Try() error = %v, tells the server was unreachable %v, want %v", err, got, tt.wantConnectivity)
+			}
+			var urlErr *url.Error
+			if got := errors.As(err, &urlErr); got != tt.wantConnectivity {
+				t.Errorf("Try() error = %v, wraps transport error %v, want %v", err, got, tt.wantConnectivity)
+			}
+		})
+	}
+
+	t.Run("should not report a connectivity error due to the context deadline", func(t *testing.T) {
+		t.Parallel()
+		err := fmt.Errorf("attempt timed out: %w", &url.Error{Op: "Get", URL: "http://localhost", Err: context.DeadlineExceeded})
+		if hardy.IsConnectivityError(err) {
+			t.Errorf("IsConnectivityError() = true, want false")
+		}
+	})
+
+	t.Run("should not report a connectivity error due to the context cancellation", func(t *testing.T) {
+		t.Parallel()
+		err := fmt.Errorf("unexpected error: %w", &url.Error{Op: "Get", URL: "http://localhost", Err: context.Canceled})
+		if hardy.IsConnectivityError(err) {
+			t.Errorf("IsConnectivityError() = true, want false")
+		}
+	})
+
+	t.Run("should not report a connectivity error due to a nil error", func(t *testing.T) {
+		t.Parallel()
+		if hardy.IsConnectivityError(nil) {
+			t.Errorf("IsConnectivityError() = true, want false")
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package hardy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// RequestFactory defines the function that builds the request to be performed against the given base URL.
+type RequestFactory func(base string) *http.Request
+
+// TryFailover tries to perform the request built by the given RequestFactory as per configurations, rotating
+// through the given base URLs as the attempts fail, so the first attempt is performed against the first base URL,
+// the second attempt against the second one and so on, cycling. The base URLs that have been consistently failing
+// in previous calls are moved to the end of the rotation. If some FallbackFunc is given, after max retries were
+// reached, it will be called. Besides the errors returned by Try, it might return ErrNoBaseURLFound if no base URL
+// was given.
+func (c *Client) TryFailover(ctx context.Context, reqFactory RequestFactory, bases []string, readerFunc ReaderFunc, fallbackFunc FallbackFunc) error {
+	if len(bases) == 0 {
+		return ErrNoBaseURLFound
+	}
+
+	// Orders the base URLs by their consecutive failures, keeping the given order for ties.
+	ordered := c.orderBases(bases)
+
+	// Builds the request for the given attempt, tracking the base URLs that failed.
+	var lastBase string
+	buildRequest := func(attempt int) (*http.Request, error) {
+		if attempt > 0 {
+			c.recordBaseOutcome(lastBase, false)
+		}
+		lastBase = ordered[attempt%len(ordered)]
+		req := reqFactory(lastBase)
+		if req == nil {
+			return nil, fmt.Errorf("no request was built for base URL %q", lastBase)
+		}
+		return req, nil
+	}
+
+	req, err := buildRequest(0)
+	if err != nil {
+		return newError(ErrUnexpected, withCause(err))
+	}
+
+	// The fallback is called only after the outcome of the last base URL is recorded.
+	_, err = c.try(ctx, req, buildRequest, readerFunc, nil, false)
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return err
+	}
+	c.recordBaseOutcome(lastBase, err == nil)
+	if err != nil && fallbackFunc != nil {
+		return c.snapshot().fallback(err, fallbackFunc)
+	}
+	return err
+}
+
+// orderBases returns the given base URLs ordered by their consecutive failures, keeping the given order for ties.
+func (c *Client) orderBases(bases []string) []string {
+	c.baseFailuresMu.Lock()
+	defer c.baseFailuresMu.Unlock()
+	ordered := append([]string(nil), bases...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return c.baseFailures[ordered[i]] < c.baseFailures[ordered[j]]
+	})
+	return ordered
+}
+
+// recordBaseOutcome records the outcome of an attempt against the given base URL, resetting its consecutive
+// failures if it succeeded.
+func (c *Client) recordBaseOutcome(base string, succeeded bool) {
+	c.baseFailuresMu.Lock()
+	defer c.baseFailuresMu.Unlock()
+	if c.baseFailures == nil {
+		c.baseFailures = make(map[string]int)
+	}
+	if succeeded {
+		delete(c.baseFailures, base)
+		return
+	}
+	c.baseFailures[base]++
+}
@@ -0,0 +1,115 @@
+package hardy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/diegohordi/hardy"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_TryFailover(t *testing.T) {
+	t.Parallel()
+
+	var primaryCalls, secondaryCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&secondaryCalls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer secondary.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(3),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqFactory := func(base string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, base+"/resource", nil)
+		return req
+	}
+	readerFunc := func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s", response.Status)
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name              string
+		bases             []string
+		fallbackFunc      hardy.FallbackFunc
+		wantErr           bool
+		errWant           error
+		wantPrimaryCalls  int32
+		wantSecondaryCall int32
+	}{
+		{
+			name:              "should fail over to the secondary base URL",
+			bases:             []string{primary.URL, secondary.URL},
+			wantPrimaryCalls:  1,
+			wantSecondaryCall: 1,
+		},
+		{
+			name:              "should try first the base URL without failures",
+			bases:             []string{primary.URL, secondary.URL},
+			wantPrimaryCalls:  1,
+			wantSecondaryCall: 2,
+		},
+		{
+			name:              "should cycle through the base URLs until max retries were reached",
+			bases:             []string{primary.URL},
+			wantErr:           true,
+			errWant:           hardy.ErrMaxRetriesReached,
+			wantPrimaryCalls:  4,
+			wantSecondaryCall: 2,
+		},
+		{
+			name:  "should call the fallback function after max retries were reached",
+			bases: []string{primary.URL},
+			fallbackFunc: func() error {
+				return nil
+			},
+			wantPrimaryCalls:  7,
+			wantSecondaryCall: 2,
+		},
+		{
+			name:              "should fail due to no base URL given",
+			wantErr:           true,
+			errWant:           hardy.ErrNoBaseURLFound,
+			wantPrimaryCalls:  7,
+			wantSecondaryCall: 2,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.TryFailover(context.TODO(), reqFactory, tt.bases, readerFunc, tt.fallbackFunc)
+			if err != nil != tt.wantErr {
+				t.Errorf("TryFailover() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("TryFailover() error = %v, errWant %v", err, tt.errWant)
+			}
+			if got := atomic.LoadInt32(&primaryCalls); got != tt.wantPrimaryCalls {
+				t.Errorf("TryFailover() primary calls = %d, want %d", got, tt.wantPrimaryCalls)
+			}
+			if got := atomic.LoadInt32(&secondaryCalls); got != tt.wantSecondaryCall {
+				t.Errorf("TryFailover() secondary calls = %d, want %d", got, tt.wantSecondaryCall)
+			}
+		})
+	}
+}
@@ -3,16 +3,28 @@
 package hardy
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"math"
 	"math/big"
+	mathrand "math/rand"
+	"net"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
@@ -30,63 +42,429 @@ const (
 	// DefaultMaxRetries is the default maximum allowed retries.
 	DefaultMaxRetries = 3
 
+	// UnlimitedRetries is the max retries that makes the client retry until the request context or the max elapsed
+	// time stop it.
+	UnlimitedRetries = -1
+
 	// DefaultBackoffMultiplier is the default backoff multiplier used to get next intervals.
 	DefaultBackoffMultiplier = 2
 
 	// DefaultTimeoutInSeconds is the maximum timeout for each attempt in seconds.
 	DefaultTimeoutInSeconds = 10
 
+	// DefaultKeepAliveInSeconds is the keep-alive period of the connections dialed by the internally created
+	// transport, in seconds.
+	DefaultKeepAliveInSeconds = 30
+
+	// DefaultExpectContinueTimeoutInSeconds is the time to wait for the server first response headers after
+	// sending the Expect: 100-continue header, in seconds.
+	DefaultExpectContinueTimeoutInSeconds = 1
+
+	// defaultMaxRedirects is the max number of redirects followed by the default HTTP Client policy.
+	defaultMaxRedirects = 10
+
+	// minAttemptTime is the minimum remaining elapsed time worth an attempt.
+	minAttemptTime = time.Millisecond
+
+	// exhaustionJitterMargin is the time kept from the context deadline to report the exhaustion after its jitter.
+	exhaustionJitterMargin = 10 * time.Millisecond
+
+	// retryAfterHeader is the header used by servers to tell how long to wait before a new attempt.
+	retryAfterHeader = "Retry-After"
+
 	// userAgentHeader is the default User-Agent header.
 	userAgentHeader = "User-Agent"
 
+	// warningHeader is the header used by servers to tell about additional issues with the response.
+	warningHeader = "Warning"
+
+	// deprecationHeader is the header used by servers to tell that the requested resource is deprecated.
+	deprecationHeader = "Deprecation"
+
+	// sunsetHeader is the header used by servers to tell when the requested resource will become unavailable.
+	sunsetHeader = "Sunset"
+
+	// traceparentHeader is the W3C header carrying the trace the request is part of.
+	traceparentHeader = "Traceparent"
+
+	// tracestateHeader is the W3C header carrying the vendor-specific trace data.
+	tracestateHeader = "Tracestate"
+
+	// expectHeader is the header used to ask the server to confirm the request before its body is sent.
+	expectHeader = "Expect"
+
+	// accessControlRequestMethodHeader is the header telling the method of the actual request in a CORS preflight.
+	accessControlRequestMethodHeader = "Access-Control-Request-Method"
+
+	// accessControlAllowMethodsHeader is the header telling the methods allowed by a CORS preflight.
+	accessControlAllowMethodsHeader = "Access-Control-Allow-Methods"
+
+	// connectionHeader is the hop-by-hop header listing the other headers meant only for the current connection.
+	connectionHeader = "Connection"
+
+	// upgradeHeader is the header asking for a protocol switch, which is listed by the Connection header.
+	upgradeHeader = "Upgrade"
+
 	// clientName is the client name used in as part of the User-Agent header.
 	clientName = "go-hardy-http-client"
 )
 
+// hopByHopHeaders are the hop-by-hop headers defined by RFC 7230, section 6.1, meant only for a single connection,
+// along with the non-standard Proxy-Connection one. The Proxy-Authorization and Upgrade headers are left out, since
+// the client itself, not being a forwarding proxy, sends them on purpose, as to authenticate against an HTTP proxy or
+// to ask for a protocol switch.
+var hopByHopHeaders = []string{
+	connectionHeader,
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+}
+
 // ReaderFunc defines the function responsible to read the HTTP response and also determines if a new retry
 // must be performed returning an error or not, returning nil.
 //
 // Keep in mind while writing your reader function that we shouldn't perform a retry if the response contains
 // an error due to a client error (400-499 HTTP error codes), but consider only the ones not caused by them instead,
-// as 500 and 503 HTTP error codes, for instance.
+// as 500 and 503 HTTP error codes, for instance. If the error is known to be fatal, wrap it with Permanent to stop
+// retrying immediately.
+//
+// The response body is closed only after the ReaderFunc returns, so the HTTP trailers are available through
+// response.Trailer once the body was fully read.
 type ReaderFunc func(response *http.Response) error
 
+// ResponseValidatorFunc defines the function responsible to validate the HTTP response before it is read by the
+// ReaderFunc. Returning an error will allow a new attempt, as a ReaderFunc error does.
+type ResponseValidatorFunc func(response *http.Response) error
+
+// BeforeRequestFunc defines the function called on each attempt right before performing the request, allowing it
+// to be mutated, as refreshing its authorization headers. Returning an error will abort the attempts, unless it
+// wraps ErrRetryRequest, which will allow a new attempt.
+type BeforeRequestFunc func(ctx context.Context, req *http.Request) error
+
+// RequestRewriterFunc defines the function called before each new attempt, but not the first one, to produce the
+// request that should be attempted, based on the last request attempted and the last response got, which is nil if
+// some transport error occurred and has its body already closed. The returned request must have a replayable body,
+// while returning nil keeps the last request.
+type RequestRewriterFunc func(req *http.Request, lastResp *http.Response, attempt int) *http.Request
+
+// RequestSigner declares the methods that the request signers should implement, as the ones required by AWS SigV4
+// or GCP signed APIs.
+type RequestSigner interface {
+
+	// Sign signs the given request, which is about to be sent. Its body, if any, should be read through GetBody, so
+	// the one being sent is kept untouched.
+	Sign(req *http.Request) error
+}
+
 // Debugger declares the methods that the debuggers should implement.
 type Debugger interface {
 	Println(v ...any)
 }
 
+// MetricsCollector declares the methods used by the client to report its retries, as to a metrics backend, which
+// must be safe for concurrent use.
+type MetricsCollector interface {
+
+	// IncRetryForStatus counts a retry scheduled after an attempt that got the given HTTP status code, which is 0
+	// if the attempt failed due to some transport error.
+	IncRetryForStatus(code int)
+}
+
+// NamedMetricsCollector declares the methods used by the client to report its retries labeled with its name, as
+// given by WithName, so the retries of several clients might be told apart. A MetricsCollector implementing it is
+// called through IncRetryForStatusNamed instead of IncRetryForStatus.
+type NamedMetricsCollector interface {
+	MetricsCollector
+
+	// IncRetryForStatusNamed counts a retry scheduled by the client with the given name, which is empty if none was
+	// given, after an attempt that got the given HTTP status code, which is 0 if the attempt failed due to some
+	// transport error.
+	IncRetryForStatusNamed(name string, code int)
+}
+
+// Clock declares the methods used by the client to get the current time and to wait between each retry,
+// allowing the time to be simulated in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the default Clock, backed by the time package.
+type realClock struct{}
+
+// Now returns the current local time.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse and then sends the current time on the returned channel.
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.NewTimer(d).C
+}
+
 // FallbackFunc defines the function that should be used as fallback when max retries was reached out.
 type FallbackFunc func() error
 
+// FallbackFuncCtx defines the function that should be used as fallback when max retries was reached out, receiving
+// the context given to TryCtx, so it can be canceled and carry deadlines.
+type FallbackFuncCtx func(ctx context.Context) error
+
 type Client struct {
 
+	// configMu guards the configuration against Reconfigure, while the calls take a snapshot of it.
+	configMu sync.RWMutex
+
+	// clientConfig holds the effective configuration, which is replaced as a whole by Reconfigure.
+	clientConfig
+
+	// baseFailuresMu guards baseFailures.
+	baseFailuresMu sync.Mutex
+
+	// baseFailures holds the consecutive failures of each base URL tried by TryFailover.
+	baseFailures map[string]int
+
+	// endpointHealthMu guards endpointHealth.
+	endpointHealthMu sync.Mutex
+
+	// endpointHealth holds the decaying failure score of each endpoint tried by TryBalanced.
+	endpointHealth map[string]endpointHealth
+}
+
+// clientConfig holds the configuration of a Client, which is copied by each call, so the attempts are performed with
+// the configuration they started with.
+type clientConfig struct {
+
+	// name labels the client, as after the downstream it calls, prefixing the messages printed by the Debugger.
+	name string
+
 	// httpClient is the HTTP Client used to make the calls.
 	httpClient *http.Client
 
 	// waitInterval determines the base duration between each fail request
 	waitInterval time.Duration
 
+	// backoffBase determines the base duration the intervals grow off after the first retry. Default 0, meaning the
+	// waitInterval.
+	backoffBase time.Duration
+
 	// maxRetries determines how many retries should be attempted
 	maxRetries int
 
 	// maxInterval determines the max interval between each fail request
 	maxInterval time.Duration
 
+	// minInterval determines the min interval between each fail request. Default 0, meaning no floor.
+	minInterval time.Duration
+
+	// exhaustionJitter determines the max random wait before reporting that the max retries were reached. Default 0,
+	// meaning no wait.
+	exhaustionJitter time.Duration
+
+	// serviceUnavailableBackoff determines the min interval after a 503 response without the Retry-After header.
+	// Default 0, meaning no floor.
+	serviceUnavailableBackoff time.Duration
+
 	// multiplier determines the multiplier that should be used to calculate the backoff interval
 	multiplier float64
 
+	// maxElapsedTime determines the max time spent on all attempts and the intervals between them. Default 0,
+	// meaning no limit.
+	maxElapsedTime time.Duration
+
+	// attemptTimeout determines the max time spent on each attempt. Default 0, meaning no limit.
+	attemptTimeout time.Duration
+
+	// manualBodyClose determines if the response body of a successful attempt is left open for the ReaderFunc.
+	// Default false.
+	manualBodyClose bool
+
 	// debug determines if each request should be dumped to the output. Default true.
 	debug bool
 
 	// Debugger that should be used to display request and response dumps. Default standard logger.
 	debugger Debugger
 
+	// debugFromContext determines per call if the attempts should be debugged, overriding debug, if given.
+	debugFromContext func(ctx context.Context) bool
+
+	// debugWriter is the writer where the raw request and response dumps are written to, if given. When given,
+	// the Debugger is used only for event messages.
+	debugWriter io.Writer
+
+	// debugBodyLimit determines the maximum number of body bytes shown in the request and response dumps. Default 0,
+	// meaning no limit.
+	debugBodyLimit int
+
 	// withUserAgentHeader determines if it should add the User-Agent header for all requests. Default true.
 	withUserAgentHeader bool
 
 	// userAgent holds the user agent that will be added as header.
 	userAgent string
+
+	// customUserAgent determines if the User-Agent header was given through WithUserAgentHeader, instead of built.
+	customUserAgent bool
+
+	// productName is the product name used as part of the User-Agent header. Default clientName.
+	productName string
+
+	// productVersion is the product version used as part of the User-Agent header. Default ClientVersion.
+	productVersion string
+
+	// userAgentSuffix is appended to the default User-Agent header, if given.
+	userAgentSuffix string
+
+	// withPlatformInfo determines if the OS and architecture should be part of the User-Agent header. Default false.
+	withPlatformInfo bool
+
+	// defaultHeaders holds the headers added to every request, unless already set by the caller.
+	defaultHeaders http.Header
+
+	// idempotencyKeyHeader is the header where the idempotency key computed for each request is set, if given.
+	idempotencyKeyHeader string
+
+	// traceContext extracts the W3C trace context propagated to the requests from their context, if given.
+	traceContext func(ctx context.Context) (traceparent, tracestate string)
+
+	// headersFromContext provides the headers added to the requests from their context, if given.
+	headersFromContext func(ctx context.Context) http.Header
+
+	// expectContinue determines if the Expect: 100-continue header should be sent along with request bodies.
+	// Default false.
+	expectContinue bool
+
+	// retryOnConnectionErrors determines if transient connection errors, like timeouts or refused connections,
+	// should allow a new attempt instead of failing immediately. Default true.
+	retryOnConnectionErrors bool
+
+	// connectionErrorRetriesEnabled determines if the connection errors retries were explicitly enabled through
+	// WithRetryOnConnectionErrors, so the convenience methods retry them for every method as well.
+	connectionErrorRetriesEnabled bool
+
+	// readerTimeout determines the max time the ReaderFunc might take to read each response. Default 0, meaning no
+	// limit.
+	readerTimeout time.Duration
+
+	// responseValidator validates each response before calling the ReaderFunc, if given.
+	responseValidator ResponseValidatorFunc
+
+	// retryLogFields provides the fields added to the message printed on each failed attempt, if given.
+	retryLogFields func(ctx context.Context) map[string]any
+
+	// retryPolicy determines if a new attempt should be performed by the convenience methods. Default
+	// DefaultRetryPolicy.
+	retryPolicy RetryPolicy
+
+	// customRetryPolicy determines if the RetryPolicy was given through WithRetryPolicy.
+	customRetryPolicy bool
+
+	// clock is the Clock used to wait between each retry. Default real clock.
+	clock Clock
+
+	// jitterFunc computes the jitter added to each interval, if given.
+	jitterFunc JitterFunc
+
+	// jitterRand is the seeded source of the jitter, if the deterministic mode is enabled.
+	jitterRand *lockedRand
+
+	// customHTTPClient determines if the HTTP Client was given through WithHttpClient.
+	customHTTPClient bool
+
+	// transportOptions holds the configurations that should be applied to the internally created transport.
+	transportOptions []transportOption
+
+	// cookieJar is the cookie jar installed on the internally created HTTP Client, if given.
+	cookieJar http.CookieJar
+
+	// redirectsConfigured determines if the redirects of the internally created HTTP Client were configured.
+	redirectsConfigured bool
+
+	// followRedirects determines if the redirects should be followed by the internally created HTTP Client. Default
+	// true.
+	followRedirects bool
+
+	// limitRedirects determines if the redirects followed by the internally created HTTP Client are bounded by
+	// maxRedirects.
+	limitRedirects bool
+
+	// maxRedirects is the max number of redirects followed by each attempt, if limited.
+	maxRedirects int
+
+	// semaphore bounds how many Try calls might be in flight at once, if given.
+	semaphore chan struct{}
+
+	// bodyRetryPredicate determines if a new attempt should be performed based on the response body, if given.
+	bodyRetryPredicate func(body []byte) bool
+
+	// keepHopByHopHeaders determines if the hop-by-hop headers should be kept in the requests sent. Default false.
+	keepHopByHopHeaders bool
+
+	// preloadResponseBody determines if the response bodies should be read into memory before calling the ReaderFunc.
+	// Default false.
+	preloadResponseBody bool
+
+	// immediateFallbackStatusCodes holds the HTTP status codes that should call the fallback immediately.
+	immediateFallbackStatusCodes map[int]struct{}
+
+	// maxRetriesForStatus holds the max retries for specific HTTP status codes, bounded by maxRetries.
+	maxRetriesForStatus map[int]int
+
+	// retryOn5xx determines if the 5xx responses should allow a new attempt without calling the ReaderFunc. Default
+	// false.
+	retryOn5xx bool
+
+	// successStatusCodes holds the HTTP status codes considered successful, if given.
+	successStatusCodes map[int]struct{}
+
+	// retryBudget is the RetryBudget debited on each retry, if given.
+	retryBudget *RetryBudget
+
+	// metricsCollector reports each retry scheduled, if given.
+	metricsCollector MetricsCollector
+
+	// bodySpillThreshold determines the size above which the buffered request bodies are spilled to temporary files.
+	// Default 0, meaning they are always buffered in memory.
+	bodySpillThreshold int64
+
+	// gzipRequestBody determines if the replayable request bodies larger than gzipMinSize should be compressed
+	// through gzip. Default false.
+	gzipRequestBody bool
+
+	// gzipMinSize determines the size above which the request bodies are compressed, if asked.
+	gzipMinSize int64
+
+	// bodyReplayPolicy determines if the body of the given request can be replayed in new attempts, if given.
+	bodyReplayPolicy func(req *http.Request) bool
+
+	// onWarningHeader is called for each warning, deprecation or sunset header got, if given.
+	onWarningHeader func(header, value string)
+
+	// shouldContinue decides after each failed attempt if a new one should be performed, if given.
+	shouldContinue func(attempt int, lastErr error, lastResp *http.Response) bool
+
+	// retryIf decides after each failed attempt if a new one should be performed, replacing the default decision, if
+	// given.
+	retryIf func(resp *http.Response, respErr error, readerErr error, attempt int) bool
+
+	// errorMapper maps the errors built by the client before they are returned, if given.
+	errorMapper func(code ErrorCode, cause error, resp *http.Response) error
+
+	// onFallback is called right before the fallback, if given.
+	onFallback func(lastErr error)
+
+	// beforeRequest is called on each attempt right before performing the request, if given.
+	beforeRequest BeforeRequestFunc
+
+	// requestSigner signs each attempt right before performing it, if given.
+	requestSigner RequestSigner
+
+	// requestRewriter produces the request for each new attempt, if given.
+	requestRewriter RequestRewriterFunc
+
+	// endpoints holds the weighted endpoints TryBalanced spreads the attempts across, if given.
+	endpoints []WeightedEndpoint
 }
 
 // NewClient creates a new Hardy wrapper with the defaults or an error if it was misconfigured by some given option.
@@ -96,19 +474,25 @@ func NewClient(options ...Option) (*Client, error) {
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 
 	// Create the client with default configuration
-	c := &Client{
+	c := &Client{clientConfig: clientConfig{
 		httpClient: &http.Client{
 			Timeout:   DefaultTimeoutInSeconds * time.Second,
 			Transport: transport,
 		},
-		waitInterval:        DefaultWaitIntervalMilliseconds * time.Millisecond,
-		maxInterval:         DefaultMaxIntervalInMilliseconds * time.Millisecond,
-		maxRetries:          DefaultMaxRetries,
-		multiplier:          DefaultBackoffMultiplier,
-		withUserAgentHeader: true,
-		debug:               true,
-		debugger:            log.Default(),
-	}
+		waitInterval:            DefaultWaitIntervalMilliseconds * time.Millisecond,
+		maxInterval:             DefaultMaxIntervalInMilliseconds * time.Millisecond,
+		maxRetries:              DefaultMaxRetries,
+		multiplier:              DefaultBackoffMultiplier,
+		withUserAgentHeader:     true,
+		productName:             clientName,
+		productVersion:          ClientVersion,
+		debug:                   true,
+		debugger:                log.Default(),
+		retryOnConnectionErrors: true,
+		followRedirects:         true,
+		retryPolicy:             DefaultRetryPolicy,
+		clock:                   realClock{},
+	}}
 
 	// Apply the given configurations
 	for i := range options {
@@ -117,14 +501,153 @@ func NewClient(options ...Option) (*Client, error) {
 		}
 	}
 
-	// build User-Agent header
-	c.setUserAgentHeader()
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	// Apply the transport configurations, which are only allowed on the internally created transport, since
+	// the one from a given HTTP Client shouldn't be mutated.
+	if len(c.transportOptions) > 0 {
+		if c.customHTTPClient {
+			return nil, newError(ErrInvalidClientConfiguration, withCause(ErrHTTPClientNotConfigurable))
+		}
+		for i := range c.transportOptions {
+			c.transportOptions[i](transport)
+		}
+	}
+
+	// The cookie jar is only installed on the internally created HTTP Client, since a given one manages its own.
+	if c.cookieJar != nil {
+		if c.customHTTPClient {
+			return nil, newError(ErrInvalidClientConfiguration, withCause(ErrHTTPClientNotConfigurable))
+		}
+		c.httpClient.Jar = c.cookieJar
+	}
+
+	// The redirects are only configured on the internally created HTTP Client, since a given one manages its own.
+	// They are checked as per a snapshot of the configuration, as they can't be reconfigured, so the redirects
+	// followed never race with Reconfigure.
+	if c.redirectsConfigured {
+		if c.customHTTPClient {
+			return nil, newError(ErrInvalidClientConfiguration, withCause(ErrHTTPClientNotConfigurable))
+		}
+		c.httpClient.CheckRedirect = c.snapshot().checkRedirect
+	}
+
 	return c, nil
 }
 
+// validate validates the configuration as a whole, as the options conflicting with each other, building the
+// User-Agent header, unless a custom one was given.
+func (c *Client) validate() error {
+
+	// The intervals can't be bounded by a max interval lower than the min one.
+	if c.minInterval > 0 && c.maxInterval > 0 && c.minInterval > c.maxInterval {
+		return newError(ErrInvalidClientConfiguration, withCause(fmt.Errorf("min interval %v can't be greater than max interval %v", c.minInterval, c.maxInterval)))
+	}
+
+	// The retry decision would be ambiguous if both the predicate and the RetryPolicy were given.
+	if c.retryIf != nil && c.customRetryPolicy {
+		return newError(ErrInvalidClientConfiguration, withCause(fmt.Errorf("retry if can't be used along with a retry policy")))
+	}
+
+	// The body left open would be canceled along with the attempt context.
+	if c.manualBodyClose && (c.attemptTimeout > 0 || c.maxElapsedTime > 0) {
+		return newError(ErrInvalidClientConfiguration, withCause(fmt.Errorf("manual body close can't be used along with attempt timeout nor max elapsed time")))
+	}
+
+	// build User-Agent header, unless a custom one was given
+	if !c.customUserAgent {
+		c.setUserAgentHeader()
+	}
+	return nil
+}
+
+// clone copies the configuration, including the collections the options add to, so the copy might be reconfigured
+// without affecting the calls performed with the original one.
+func (cfg clientConfig) clone() clientConfig {
+	cfg.transportOptions = append([]transportOption(nil), cfg.transportOptions...)
+	if cfg.immediateFallbackStatusCodes != nil {
+		immediateFallbackStatusCodes := make(map[int]struct{}, len(cfg.immediateFallbackStatusCodes))
+		for statusCode := range cfg.immediateFallbackStatusCodes {
+			immediateFallbackStatusCodes[statusCode] = struct{}{}
+		}
+		cfg.immediateFallbackStatusCodes = immediateFallbackStatusCodes
+	}
+	if cfg.successStatusCodes != nil {
+		successStatusCodes := make(map[int]struct{}, len(cfg.successStatusCodes))
+		for statusCode := range cfg.successStatusCodes {
+			successStatusCodes[statusCode] = struct{}{}
+		}
+		cfg.successStatusCodes = successStatusCodes
+	}
+	return cfg
+}
+
+// snapshot returns a client holding a copy of the current configuration, which the calls perform their attempts
+// with, so they neither hold the configuration lock, which would block Reconfigure as well as the hooks reading the
+// configuration, nor see a reconfiguration midway. The state kept across calls, as the failures of the base URLs,
+// is not copied, so it must be tracked through the original client.
+func (c *Client) snapshot() *Client {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	return &Client{clientConfig: c.clientConfig}
+}
+
+// Reconfigure applies the given options to the client at runtime, as bumping the max retries or swapping the debugger
+// on a configuration reload, keeping its connection pools. The options are applied to a copy of the effective
+// configuration, validated the same way NewClient does, and the copy replaces the configuration only if they are all
+// valid. The options that can't be safely changed at runtime, as the HTTP Client, its transport and the max
+// concurrency, return an ErrInvalidClientConfiguration.
+//
+// The calls in flight, as the long-lived streams, keep performing their attempts with the configuration they started
+// with, while the new calls use the new one.
+func (c *Client) Reconfigure(options ...Option) error {
+
+	// Checks if any option would replace the HTTP Client, its transport or the concurrency bounds.
+	probe := &Client{}
+	for i := range options {
+		if err := options[i](probe); err != nil {
+			return newError(ErrInvalidClientConfiguration, withCause(err))
+		}
+	}
+	if probe.httpClient != nil || len(probe.transportOptions) > 0 || probe.cookieJar != nil || probe.redirectsConfigured || probe.semaphore != nil {
+		return newError(ErrInvalidClientConfiguration, withCause(fmt.Errorf("the HTTP Client, its transport, its cookie jar, its redirects and the max concurrency can't be reconfigured")))
+	}
+
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+
+	// Applies the options to a copy of the effective configuration, which replaces it only if valid.
+	next := &Client{clientConfig: c.clientConfig.clone()}
+	for i := range options {
+		if err := options[i](next); err != nil {
+			return newError(ErrInvalidClientConfiguration, withCause(err))
+		}
+	}
+	if err := next.validate(); err != nil {
+		return err
+	}
+	c.clientConfig = next.clientConfig
+	return nil
+}
+
+// MustNewClient creates a new Hardy wrapper as NewClient does, but panics if it was misconfigured by some given
+// option. It is intended for package-level variables initialization with known-good configurations.
+func MustNewClient(options ...Option) *Client {
+	c, err := NewClient(options...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
 // Option defines the optional configurations for the Client.
 type Option func(c *Client) error
 
+// transportOption defines the configurations applied to the internally created transport.
+type transportOption func(transport *http.Transport)
+
 // WithDebugger enables the debug mode, dumping the requests to output using the client logger.
 func WithDebugger(debugger Debugger) Option {
 	return func(c *Client) error {
@@ -137,6 +660,72 @@ func WithDebugger(debugger Debugger) Option {
 	}
 }
 
+// WithDebugWriter enables the debug mode, writing the raw request and response dumps to the given writer, without
+// the Debugger formatting. The Debugger is still used for the event messages.
+func WithDebugWriter(w io.Writer) Option {
+	return func(c *Client) error {
+		if w == nil {
+			return ErrNoDebuggerFound
+		}
+		c.debug = true
+		c.debugWriter = w
+		return nil
+	}
+}
+
+// WithDebugBodyLimit determines the maximum number of body bytes shown in the request and response dumps, followed
+// by a truncation marker. The bodies used by the actual request and response are not affected. Default 0, meaning
+// no limit.
+func WithDebugBodyLimit(n int) Option {
+	return func(c *Client) error {
+		if n < 0 {
+			return fmt.Errorf("debug body limit must not be negative: %d", n)
+		}
+		c.debugBodyLimit = n
+		return nil
+	}
+}
+
+// WithRetryLogFields determines the function providing the fields that should be added to the messages printed by
+// the Debugger on each attempt, as trace or tenant IDs taken from the request context. The fields are
+// appended as key=value pairs, sorted by key.
+func WithRetryLogFields(fields func(ctx context.Context) map[string]any) Option {
+	return func(c *Client) error {
+		if fields == nil {
+			return fmt.Errorf("no retry log fields function was given")
+		}
+		c.retryLogFields = fields
+		return nil
+	}
+}
+
+// WithDebugFromContext determines the function deciding, based on the context of each call, if its attempts should
+// be debugged, as for sampled traces, overriding the debug mode. When it returns true, the requests and responses are
+// dumped even if the debug mode is disabled, while when it returns false, nothing is dumped.
+func WithDebugFromContext(debugFromContext func(ctx context.Context) bool) Option {
+	return func(c *Client) error {
+		if debugFromContext == nil {
+			return fmt.Errorf("no debug from context function was given")
+		}
+		c.debugFromContext = debugFromContext
+		return nil
+	}
+}
+
+// WithName labels the client, as after the downstream it calls, so services with several clients can tell their
+// messages apart. The messages printed by the Debugger are prefixed with the name between brackets, the contexts
+// given to the hooks, as well as the ones of the requests attempted, carry it to be got through ClientNameFrom, and
+// the NamedMetricsCollector is given it along with each retry. Default empty, meaning no prefix.
+func WithName(name string) Option {
+	return func(c *Client) error {
+		if hasControlCharacters(name) {
+			return fmt.Errorf("invalid name: %q", name)
+		}
+		c.name = name
+		return nil
+	}
+}
+
 // WithDebugDisabled disables the debug mode.
 func WithDebugDisabled() Option {
 	return func(c *Client) error {
@@ -160,208 +749,2267 @@ func WithHttpClient(httpClient *http.Client) Option {
 			return ErrNoHTTPClientFound
 		}
 		c.httpClient = httpClient
+		c.customHTTPClient = true
 		return nil
 	}
 }
 
-// WithUserAgentHeader enables adding the User-Agent header in the request and overrides the default one.
+// WithUserAgentHeader enables adding the User-Agent header in the request and overrides the default one. Values with
+// control characters, as CR and LF, are rejected.
 func WithUserAgentHeader(userAgent string) Option {
 	return func(c *Client) error {
+		if hasControlCharacters(userAgent) {
+			return fmt.Errorf("invalid User-Agent header %q: control characters are not allowed", userAgent)
+		}
 		if userAgent != "" {
 			c.userAgent = userAgent
+			c.customUserAgent = true
 		}
 		c.withUserAgentHeader = true
 		return nil
 	}
 }
 
-// WithWaitInterval determines the base duration between each fail request.
-func WithWaitInterval(interval time.Duration) Option {
+// WithDefaultHeaders determines the headers that should be added to every request, as Accept or X-Api-Version.
+// Headers already set in the request are kept, since the per-request ones take precedence. Names that aren't valid
+// tokens, as the ones with spaces, colons or control characters, and values with control characters, as CR and LF,
+// are rejected.
+func WithDefaultHeaders(headers http.Header) Option {
 	return func(c *Client) error {
-		c.waitInterval = interval
+		if len(headers) == 0 {
+			return fmt.Errorf("no default header was given")
+		}
+		c.defaultHeaders = make(http.Header, len(headers))
+		for key, values := range headers {
+			if !isValidHeaderName(key) {
+				return fmt.Errorf("invalid default header name %q", key)
+			}
+			for i := range values {
+				if hasControlCharacters(values[i]) {
+					return fmt.Errorf("invalid default header %s value %q: control characters are not allowed", key, values[i])
+				}
+			}
+			c.defaultHeaders[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+		}
 		return nil
 	}
 }
 
-// WithMaxRetries determines how many retries should be attempted.
-func WithMaxRetries(maxRetries int) Option {
+// WithHeadersFromContext determines the function providing the headers that should be added to every attempt from
+// the context of its call, as tenant IDs or locales taken from context values, being the dynamic counterpart of
+// WithDefaultHeaders. The headers are computed for each attempt and added to its copy of the request, so the one
+// given is never mutated. Headers already set in the request are kept, while the ones given take precedence over the
+// default headers. Invalid names or values, as the ones with control characters, are dropped.
+func WithHeadersFromContext(headers func(ctx context.Context) http.Header) Option {
 	return func(c *Client) error {
-		c.maxRetries = maxRetries
+		if headers == nil {
+			return fmt.Errorf("no headers from context function was given")
+		}
+		c.headersFromContext = headers
 		return nil
 	}
 }
 
-// WithMaxInterval determines the max interval between each fail request.
-func WithMaxInterval(interval time.Duration) Option {
+// WithTraceContextPropagation determines the function extracting the W3C trace context, as the traceparent and
+// tracestate headers, from the context of each call, so it is propagated to all its attempts, as for OpenTelemetry
+// users not willing to wrap the transport. Headers already set in the request, as well as empty values or the ones
+// with control characters, are not propagated.
+func WithTraceContextPropagation(extract func(ctx context.Context) (traceparent, tracestate string)) Option {
 	return func(c *Client) error {
-		c.maxInterval = interval
+		if extract == nil {
+			return fmt.Errorf("no trace context extractor was given")
+		}
+		c.traceContext = extract
 		return nil
 	}
 }
 
-// WithBackoffMultiplier Determines the multiplier that should be used to calculate the backoff interval.
-func WithBackoffMultiplier(multiplier float64) Option {
+// WithClientIdentity overrides the product name and version used to build the default User-Agent header, as
+// "myapp/1.2.3 (go1.19)". If no name is given, the hardy identity is kept.
+func WithClientIdentity(name, version string) Option {
 	return func(c *Client) error {
-		if multiplier < DefaultBackoffMultiplier {
+		if name == "" {
 			return nil
 		}
-		c.multiplier = multiplier
+		if hasControlCharacters(name) || hasControlCharacters(version) {
+			return fmt.Errorf("invalid client identity %q %q: control characters are not allowed", name, version)
+		}
+		c.productName = name
+		c.productVersion = version
 		return nil
 	}
 }
 
-// setUserAgentHeader sets the User-Agent information that will be sent as header, accordingly to RFC7231.
-func (c *Client) setUserAgentHeader() {
-	userAgentFormatString := "%s/%s (%s)"
-	c.userAgent = fmt.Sprintf(userAgentFormatString, clientName, ClientVersion, runtime.Version())
+// WithUserAgentSuffix appends the given suffix to the default User-Agent header, as
+// "go-hardy-http-client/0.2.0 (go1.19) myapp/2.0", keeping the library identification. It doesn't affect a custom
+// User-Agent header given through WithUserAgentHeader.
+func WithUserAgentSuffix(suffix string) Option {
+	return func(c *Client) error {
+		suffix = strings.TrimSpace(suffix)
+		if suffix == "" {
+			return fmt.Errorf("no User-Agent suffix was given")
+		}
+		if hasControlCharacters(suffix) {
+			return fmt.Errorf("invalid User-Agent suffix %q: control characters are not allowed", suffix)
+		}
+		c.userAgentSuffix = suffix
+		return nil
+	}
 }
 
-// getInterval calculates the interval between each retry based on the given attempt and the client configuration.
-func (c *Client) getInterval(waitInterval, maxInterval time.Duration, attempt int, multiplier float64) time.Duration {
-	backoff := waitInterval.Milliseconds() * int64(math.Pow(multiplier, float64(attempt)))
-	random, err := rand.Int(rand.Reader, big.NewInt(1000))
-	if err != nil {
-		return time.Duration(backoff) * time.Millisecond
-	}
-	totalInterval := time.Duration(backoff+random.Int64()) * time.Millisecond
-	if maxInterval == 0 {
-		return totalInterval
-	}
-	if totalInterval > maxInterval {
-		return maxInterval
+// WithUserAgentPlatformInfo adds the OS and architecture to the default User-Agent header, as
+// "go-hardy-http-client/0.2.0 (go1.19; linux/amd64)". It is disabled by default to avoid leaking platform
+// information unintentionally.
+func WithUserAgentPlatformInfo() Option {
+	return func(c *Client) error {
+		c.withPlatformInfo = true
+		return nil
 	}
-	return totalInterval
 }
 
-// Try tries to perform the given request as per configurations. If some FallbackFunc is given,
-// after max retries were reached, it will be called. It might return the following errors:
-//
-// - ErrNoReaderFuncFound - when no reader function was provided.
-//
-// - ErrMaxRetriesReached - if max retries were reached.
-//
-// - context.DeadlineExceeded or context.Canceled - if the given context was gone.
-//
-// - ErrUnexpected is the error returned when no one of the previous errors match.
-func (c *Client) Try(ctx context.Context, req *http.Request, readerFunc ReaderFunc, fallbackFunc FallbackFunc) error {
-
-	// Checks if a reader function was given
-	if readerFunc == nil {
-		return ErrNoReaderFuncFound
+// WithWaitInterval determines the base duration between each fail request.
+func WithWaitInterval(interval time.Duration) Option {
+	return func(c *Client) error {
+		c.waitInterval = interval
+		return nil
 	}
+}
 
-	// Sets the User-Agent header if asked
-	if c.withUserAgentHeader {
-		req.Header.Add(userAgentHeader, c.userAgent)
-	} else {
-		if c.debug {
-			if v := req.Header.Get(userAgentHeader); v == "" {
-				c.debugger.Println("no User-Agent was given")
-			}
+// WithBackoffBase determines the base duration the intervals grow off, apart from the wait interval, which only
+// determines the first interval. So the nth retry waits the base multiplied by the multiplier raised to n+1, plus the
+// jitter, except for the first one, which waits the wait interval multiplied by the multiplier squared, as 400ms, 8s,
+// 16s and so on for a 100ms wait interval, a 1s base and a multiplier of 2. If not given, the base is the wait
+// interval, so the intervals are the same as setting it to the wait interval.
+func WithBackoffBase(base time.Duration) Option {
+	return func(c *Client) error {
+		if base <= 0 {
+			return fmt.Errorf("backoff base must be positive: %v", base)
 		}
+		c.backoffBase = base
+		return nil
 	}
+}
 
-	// Create channels to receive some error or the signal that the request was successfully performed.
-	errChan := make(chan error, 1)
-	resultChan := make(chan struct{}, 1)
+// WithMaxRetries determines how many retries should be attempted. UnlimitedRetries makes the client retry until the
+// request context or the max elapsed time stop it, as WithUnlimitedRetries does.
+func WithMaxRetries(maxRetries int) Option {
+	return func(c *Client) error {
+		c.maxRetries = maxRetries
+		return nil
+	}
+}
 
-	// Sends the request
-	go c.sendRequest(ctx, req, readerFunc, errChan, resultChan)
+// WithUnlimitedRetries makes the client retry until the request context is gone or the max elapsed time given by
+// WithMaxElapsedTime is reached, as for background sync jobs, never returning ErrMaxRetriesReached due to the count
+// of attempts. The max retries given by WithMaxRetriesForStatus are still honored. Since it would otherwise retry
+// forever, a call whose context can't be canceled fails with ErrInvalidClientConfiguration if no max elapsed time
+// was given.
+func WithUnlimitedRetries() Option {
+	return WithMaxRetries(UnlimitedRetries)
+}
+
+// WithMaxRetriesForStatus determines how many retries should be attempted when the last attempt got a response with
+// one of the given HTTP status codes. The max retries given by WithMaxRetries still acts as a ceiling, being also used
+// for unlisted status codes, as well as for the attempts that got no response, as due to connection errors.
+func WithMaxRetriesForStatus(maxRetries map[int]int) Option {
+	return func(c *Client) error {
+		if len(maxRetries) == 0 {
+			return fmt.Errorf("no max retries for status was given")
+		}
+		c.maxRetriesForStatus = make(map[int]int, len(maxRetries))
+		for statusCode, n := range maxRetries {
+			if n <= 0 {
+				return fmt.Errorf("invalid max retries %d for status %d", n, statusCode)
+			}
+			c.maxRetriesForStatus[statusCode] = n
+		}
+		return nil
+	}
+}
+
+// WithMaxInterval determines the max interval between each fail request.
+func WithMaxInterval(interval time.Duration) Option {
+	return func(c *Client) error {
+		c.maxInterval = interval
+		return nil
+	}
+}
+
+// WithMinInterval determines the min interval between each fail request, so the intervals never drop below it, as
+// required by rate limited APIs. It is applied after the jitter and before the max interval, which can't be lower.
+func WithMinInterval(interval time.Duration) Option {
+	return func(c *Client) error {
+		if interval <= 0 {
+			return fmt.Errorf("min interval must be positive: %v", interval)
+		}
+		c.minInterval = interval
+		return nil
+	}
+}
+
+// WithServiceUnavailableBackoff determines the min interval before a new attempt after a 503 response without the
+// Retry-After header, layered on top of the regular backoff, since the server is clearly overloaded. When the header
+// is present, it is left to the server to tell how long to wait.
+func WithServiceUnavailableBackoff(interval time.Duration) Option {
+	return func(c *Client) error {
+		if interval <= 0 {
+			return fmt.Errorf("service unavailable backoff must be positive: %v", interval)
+		}
+		c.serviceUnavailableBackoff = interval
+		return nil
+	}
+}
+
+// WithExhaustionJitter determines the max random wait before reporting that the max retries were reached, and so
+// before the fallback is called, decorrelating the exhaustion timing of clients that started together, which would
+// otherwise cause synchronized fallback stampedes. The wait is cut to fit the request context deadline, if any, and
+// skipped if it doesn't fit, so the exhaustion is still reported, and the fallback called, before the deadline.
+func WithExhaustionJitter(max time.Duration) Option {
+	return func(c *Client) error {
+		if max <= 0 {
+			return fmt.Errorf("exhaustion jitter must be positive: %v", max)
+		}
+		c.exhaustionJitter = max
+		return nil
+	}
+}
+
+// WithBackoffMultiplier Determines the multiplier that should be used to calculate the backoff interval. It can't be
+// lower than 1, which means a constant backoff, allowing gentle backoffs as 1.5.
+func WithBackoffMultiplier(multiplier float64) Option {
+	return func(c *Client) error {
+		if multiplier < 1 {
+			return fmt.Errorf("invalid backoff multiplier %v, it can't be lower than 1", multiplier)
+		}
+		c.multiplier = multiplier
+		return nil
+	}
+}
+
+// WithMaxElapsedTime determines the max time spent on all attempts of a request and the intervals between them.
+// Once it is reached, or if the next interval would reach it, no new attempt is performed and ErrMaxElapsedTimeReached
+// is returned. Each attempt is also bounded by the remaining elapsed time, and the request context deadline, if any,
+// bounds the whole.
+func WithMaxElapsedTime(maxElapsedTime time.Duration) Option {
+	return func(c *Client) error {
+		if maxElapsedTime <= 0 {
+			return fmt.Errorf("max elapsed time must be positive: %v", maxElapsedTime)
+		}
+		c.maxElapsedTime = maxElapsedTime
+		return nil
+	}
+}
+
+// WithAttemptTimeout determines the max time spent on each attempt, including the reading of the response by the
+// ReaderFunc. An attempt that times out allows a new one. The effective timeout of each attempt is the lower between
+// the given one and the remaining time given by WithMaxElapsedTime, as well as the request context deadline.
+func WithAttemptTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return fmt.Errorf("attempt timeout must be positive: %v", timeout)
+		}
+		c.attemptTimeout = timeout
+		return nil
+	}
+}
+
+// WithReaderTimeout determines the max time the ReaderFunc might take to read each response, so a hung reader can't
+// block the attempts. Once it is exceeded, the attempt is considered failed, allowing a new one, and the response body
+// is closed, which should interrupt a reader blocked on it. Since a ReaderFunc can't be stopped, it keeps running in
+// the background until it returns, so it must be safe to be called concurrently with the next attempts. It doesn't
+// apply to TryStream handlers.
+func WithReaderTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return fmt.Errorf("reader timeout must be positive: %v", timeout)
+		}
+		c.readerTimeout = timeout
+		return nil
+	}
+}
+
+// WithManualBodyClose disables the automatic close of the response body after the ReaderFunc returns nil, so the
+// response returned by Do or TryResponse keeps its body open to be streamed after they return. The bodies of failed
+// attempts are still closed, since their responses are discarded. The other methods, which never hand the response
+// body back, fail with ErrInvalidClientConfiguration.
+//
+// Use it carefully: every response returned MUST have its body closed by the caller, otherwise the underlying
+// connections will leak. It can't be used along with WithAttemptTimeout nor WithMaxElapsedTime, which would cancel
+// the body once the attempt is over.
+func WithManualBodyClose() Option {
+	return func(c *Client) error {
+		c.manualBodyClose = true
+		return nil
+	}
+}
+
+// WithRetryOnConnectionErrors enables new attempts when the request fails due to transient connection errors,
+// as timeouts, connection resets or refused connections, whatever the method of the request. Any other transport
+// error still fails immediately. It is the default behavior, except for the convenience methods, as Do, which by
+// default, as per DefaultRetryPolicy, only retry the non-idempotent requests on refused connections, unless they have
+// an Idempotency-Key header, or a custom RetryPolicy or retry predicate was given.
+func WithRetryOnConnectionErrors() Option {
+	return func(c *Client) error {
+		c.retryOnConnectionErrors = true
+		c.connectionErrorRetriesEnabled = true
+		return nil
+	}
+}
+
+// WithNoRetryOnTransportErrors disables new attempts when the request fails due to transport errors, failing
+// immediately with ErrUnexpected instead.
+func WithNoRetryOnTransportErrors() Option {
+	return func(c *Client) error {
+		c.retryOnConnectionErrors = false
+		c.connectionErrorRetriesEnabled = false
+		return nil
+	}
+}
+
+// WithResponseValidator determines the function used to validate each response before calling the ReaderFunc,
+// as checking its Content-Type or signature headers. A validation error will allow a new attempt.
+func WithResponseValidator(validator ResponseValidatorFunc) Option {
+	return func(c *Client) error {
+		c.responseValidator = validator
+		return nil
+	}
+}
+
+// WithRetryPolicy overrides the RetryPolicy used by the convenience methods, as Do, Post, TryHead and TryStream, to
+// determine if a new attempt should be performed. Default DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) error {
+		if policy == nil {
+			return fmt.Errorf("no retry policy was given")
+		}
+		c.retryPolicy = policy
+		c.customRetryPolicy = true
+		return nil
+	}
+}
+
+// WithClock overrides the Clock used to wait between each retry, as well as for the reader timeout, which is mostly
+// useful to simulate the time in tests without sleeping. The context deadline is still checked against the real time.
+func WithClock(clock Clock) Option {
+	return func(c *Client) error {
+		if clock == nil {
+			return ErrNoClockFound
+		}
+		c.clock = clock
+		return nil
+	}
+}
+
+// WithJitterFunc determines the JitterFunc used to compute the jitter added to each interval, from the interval
+// computed by the backoff, as FullJitter or EqualJitter, instead of the default random jitter up to one second. The
+// interval with the jitter is still clamped to the max interval. A deterministic JitterFunc makes the intervals
+// reproducible, while WithDeterministic doesn't affect it.
+func WithJitterFunc(jitterFunc JitterFunc) Option {
+	return func(c *Client) error {
+		if jitterFunc == nil {
+			return fmt.Errorf("no jitter function was given")
+		}
+		c.jitterFunc = jitterFunc
+		return nil
+	}
+}
+
+// WithDeterministic seeds the jitter added to each interval with the given seed, so the same sequence of intervals
+// is produced run-to-run. Along with WithClock, the whole retry sequence becomes reproducible. It is intended for
+// replaying captured traffic and golden tests, not for production, where the jitter should be random.
+func WithDeterministic(seed int64) Option {
+	return func(c *Client) error {
+		c.jitterRand = &lockedRand{rand: mathrand.New(mathrand.NewSource(seed))}
+		return nil
+	}
+}
+
+// WithMaxIdleConnsPerHost determines the maximum idle connections to keep per host in the internally created
+// transport. It can't be used along with WithHttpClient.
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(c *Client) error {
+		if n < 0 {
+			return fmt.Errorf("max idle connections per host must not be negative: %d", n)
+		}
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			transport.MaxIdleConnsPerHost = n
+		})
+		return nil
+	}
+}
+
+// WithIdleConnTimeout determines the maximum amount of time an idle connection will remain idle before closing
+// itself in the internally created transport. It can't be used along with WithHttpClient.
+func WithIdleConnTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			transport.IdleConnTimeout = timeout
+		})
+		return nil
+	}
+}
+
+// WithConnectTimeout determines the maximum amount of time to wait for a connection to be established by the
+// internally created transport, so a black-holed host fails fast instead of consuming the whole timeout on the dial.
+// Such a timeout is a transient connection error, allowing a new attempt. It can't be used along with WithHttpClient.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return fmt.Errorf("connect timeout must be positive: %v", timeout)
+		}
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			dialer := &net.Dialer{
+				Timeout:   timeout,
+				KeepAlive: DefaultKeepAliveInSeconds * time.Second,
+			}
+			transport.DialContext = dialer.DialContext
+		})
+		return nil
+	}
+}
+
+// WithDisableKeepAlives determines if the internally created transport should use each connection for a single
+// request, so each attempt uses a fresh connection instead of some idle one that might have been silently dropped, as
+// by aggressive load balancers. It comes at the cost of a new connection, and its TLS handshake, for every attempt.
+// It can't be used along with WithHttpClient.
+func WithDisableKeepAlives(disable bool) Option {
+	return func(c *Client) error {
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			transport.DisableKeepAlives = disable
+		})
+		return nil
+	}
+}
+
+// WithCookieJar determines the cookie jar installed on the internally created HTTP Client, as for session-based APIs,
+// so the cookies set by each response are sent along with the next attempts and the subsequent requests. It can't be
+// used along with WithHttpClient, since a given HTTP Client manages its own jar.
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(c *Client) error {
+		if jar == nil {
+			return fmt.Errorf("no cookie jar was given")
+		}
+		c.cookieJar = jar
+		return nil
+	}
+}
+
+// WithMaxRedirects determines the max number of redirects followed by the internally created HTTP Client on each
+// attempt, so a redirect loop fails fast with ErrTooManyRedirects, without new attempts, instead of compounding with
+// the retries. Zero means no redirect is followed. It can't be used along with WithHttpClient, since a given HTTP
+// Client manages its own redirects.
+func WithMaxRedirects(n int) Option {
+	return func(c *Client) error {
+		if n < 0 {
+			return fmt.Errorf("max redirects must not be negative: %d", n)
+		}
+		c.redirectsConfigured = true
+		c.limitRedirects = true
+		c.maxRedirects = n
+		return nil
+	}
+}
+
+// WithFollowRedirects determines if the redirects should be followed by the internally created HTTP Client, which
+// they are by default. If not, the ReaderFunc gets the 3xx responses, along with their Location header, as for short
+// links resolvers or signed URL flows. Such responses are handled as any other, so they are only retried if the
+// ReaderFunc returns an error, which it shouldn't, since they aren't transient. It can't be used along with
+// WithHttpClient, since a given HTTP Client manages its own redirects.
+func WithFollowRedirects(follow bool) Option {
+	return func(c *Client) error {
+		c.redirectsConfigured = true
+		c.followRedirects = follow
+		return nil
+	}
+}
+
+// WithProxy determines the proxy URL that should be used by the internally created transport. It can't be used
+// along with WithHttpClient.
+func WithProxy(proxyURL string) Option {
+	return func(c *Client) error {
+		parsedURL, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		if parsedURL.Scheme == "" || parsedURL.Host == "" {
+			return fmt.Errorf("invalid proxy URL: %q", proxyURL)
+		}
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			transport.Proxy = http.ProxyURL(parsedURL)
+		})
+		return nil
+	}
+}
+
+// WithProxyFromEnvironment determines that the internally created transport should use the proxy given by the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables, which is already the default behavior, but makes it
+// explicit regardless of other transport customizations. Along with WithProxy, the last one given wins. It can't be
+// used along with WithHttpClient.
+func WithProxyFromEnvironment() Option {
+	return func(c *Client) error {
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			transport.Proxy = http.ProxyFromEnvironment
+		})
+		return nil
+	}
+}
+
+// WithTLSConfig determines the TLS configuration used by the internally created transport, as custom root CAs or
+// client certificates. It can't be used along with WithHttpClient.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Client) error {
+		if tlsConfig == nil {
+			return fmt.Errorf("no TLS configuration was given")
+		}
+		// Each transport gets its own copy, so the options mutating it don't leak into the other clients.
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			transport.TLSClientConfig = tlsConfig.Clone()
+		})
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the server certificate chain and host name by the internally
+// created transport. It should be used only in development environments, since it makes the requests susceptible
+// to machine-in-the-middle attacks. It can't be used along with WithHttpClient.
+func WithInsecureSkipVerify() Option {
+	return func(c *Client) error {
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			if transport.TLSClientConfig == nil {
+				transport.TLSClientConfig = &tls.Config{}
+			}
+			transport.TLSClientConfig.InsecureSkipVerify = true
+		})
+		return nil
+	}
+}
+
+// WithMinTLSVersion determines the minimum TLS version accepted by the internally created transport, as
+// tls.VersionTLS12, so the handshakes with servers supporting only older versions fail without new attempts. A TLS
+// configuration given through WithTLSConfig after it replaces the whole configuration, including the minimum version.
+// It can't be used along with WithHttpClient.
+func WithMinTLSVersion(v uint16) Option {
+	return func(c *Client) error {
+		if v < tls.VersionTLS10 || v > tls.VersionTLS13 {
+			return fmt.Errorf("invalid min TLS version: %#04x", v)
+		}
+		// The configuration is copied before being changed, since it might be shared with other transports.
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			tlsConfig := &tls.Config{}
+			if transport.TLSClientConfig != nil {
+				tlsConfig = transport.TLSClientConfig.Clone()
+			}
+			tlsConfig.MinVersion = v
+			transport.TLSClientConfig = tlsConfig
+		})
+		return nil
+	}
+}
+
+// WithResponseHeaderTimeout determines the maximum amount of time to wait for the response headers after the
+// request was written by the internally created transport, so a stalled server fails fast. Such a timeout is a
+// transient connection error, allowing a new attempt. It can't be used along with WithHttpClient.
+func WithResponseHeaderTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout < 0 {
+			return fmt.Errorf("response header timeout must not be negative: %v", timeout)
+		}
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			transport.ResponseHeaderTimeout = timeout
+		})
+		return nil
+	}
+}
+
+// WithMaxResponseHeaderBytes determines the max size of the response headers accepted by the internally created
+// transport, so a server returning gigantic headers can't exhaust the memory, as when calling untrusted endpoints.
+// Exceeding it fails with ErrResponseHeadersTooLarge without new attempts. It can't be used along with
+// WithHttpClient.
+func WithMaxResponseHeaderBytes(n int64) Option {
+	return func(c *Client) error {
+		if n <= 0 {
+			return fmt.Errorf("max response header bytes must be positive: %d", n)
+		}
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			transport.MaxResponseHeaderBytes = n
+		})
+		return nil
+	}
+}
+
+// WithForceHTTP2 determines if the internally created transport should try to use HTTP/2. It can't be used along
+// with WithHttpClient.
+func WithForceHTTP2(force bool) Option {
+	return func(c *Client) error {
+		c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+			transport.ForceAttemptHTTP2 = force
+		})
+		return nil
+	}
+}
+
+// WithExpect100Continue determines if the Expect: 100-continue header should be sent along with request bodies, so
+// the server might reject large uploads before they are streamed. The body is sent again on each new attempt, and
+// a rejection with 417 HTTP status code fails with ErrExpectationFailed without new attempts. It can't be used
+// along with WithHttpClient when enabled.
+func WithExpect100Continue(enabled bool) Option {
+	return func(c *Client) error {
+		c.expectContinue = enabled
+		if enabled {
+			c.transportOptions = append(c.transportOptions, func(transport *http.Transport) {
+				transport.ExpectContinueTimeout = DefaultExpectContinueTimeoutInSeconds * time.Second
+			})
+		}
+		return nil
+	}
+}
+
+// WithAutoIdempotencyKey determines the header where an idempotency key, computed as the SHA-256 of the request
+// method, URL and body, should be set, so the server might dedupe the retries of non-idempotent requests, as POSTs.
+// The key is computed once per call, buffering the body if needed, and kept across all its attempts. A header
+// already set by the caller is never overridden, while requests with a not replayable body, which are attempted only
+// once, get no key. Along with the "Idempotency-Key" header, it makes the 5xx of such requests retriable by
+// DefaultRetryPolicy.
+func WithAutoIdempotencyKey(headerName string) Option {
+	return func(c *Client) error {
+		if headerName == "" || hasControlCharacters(headerName) {
+			return fmt.Errorf("invalid idempotency key header name %q", headerName)
+		}
+		c.idempotencyKeyHeader = http.CanonicalHeaderKey(headerName)
+		return nil
+	}
+}
+
+// WithBodyRetryPredicate determines the predicate used to check if a new attempt should be performed based on the
+// response body, as APIs returning 200 with an error payload. The body is buffered once, so the ReaderFunc still
+// receives it untouched.
+func WithBodyRetryPredicate(predicate func(body []byte) bool) Option {
+	return func(c *Client) error {
+		c.bodyRetryPredicate = predicate
+		return nil
+	}
+}
+
+// WithKeepHopByHopHeaders determines that the hop-by-hop headers given should be kept in the requests sent, which
+// are otherwise stripped from each attempt, as well-behaved proxies do, since the stale ones might break the retries.
+// The stripped headers are Connection, along with the ones it lists, Proxy-Connection, Keep-Alive,
+// Proxy-Authenticate, Te, Trailer and Transfer-Encoding, while the Proxy-Authorization and Upgrade headers, as well as
+// a Connection header asking for the upgrade, are always kept. It should be used when some of them is meant to reach
+// the server.
+func WithKeepHopByHopHeaders() Option {
+	return func(c *Client) error {
+		c.keepHopByHopHeaders = true
+		return nil
+	}
+}
+
+// WithPreloadResponseBody determines that each response body should be read into memory before calling the
+// ReaderFunc, which then gets a body that might be read multiple times, by seeking it back to its start through
+// io.Seeker, regardless of the debug dumps. Failing to read the body allows a new attempt. It doesn't affect the
+// streamed responses.
+func WithPreloadResponseBody() Option {
+	return func(c *Client) error {
+		c.preloadResponseBody = true
+		return nil
+	}
+}
+
+// WithImmediateFallbackOn determines the HTTP status codes that should call the FallbackFunc immediately, without
+// calling the ReaderFunc nor performing new attempts, as serving a default value on a 404 HTTP status code.
+func WithImmediateFallbackOn(statusCodes ...int) Option {
+	return func(c *Client) error {
+		if c.immediateFallbackStatusCodes == nil {
+			c.immediateFallbackStatusCodes = make(map[int]struct{}, len(statusCodes))
+		}
+		for i := range statusCodes {
+			c.immediateFallbackStatusCodes[statusCodes[i]] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// WithRetryBudget determines the RetryBudget debited on each retry, which might be shared across clients calling
+// the same backend. Once the budget is exhausted, no retries are performed until successful requests refill it.
+func WithRetryBudget(budget *RetryBudget) Option {
+	return func(c *Client) error {
+		if budget == nil {
+			return fmt.Errorf("no retry budget was given")
+		}
+		c.retryBudget = budget
+		return nil
+	}
+}
+
+// WithRetryMetricsByStatus determines the MetricsCollector that should count each retry scheduled by the HTTP
+// status code of the failed attempt, or by 0 if it failed due to some transport error, so the retries of an
+// overloaded backend, as 503, might be told apart from the rate limited ones, as 429. A NamedMetricsCollector is
+// also given the client name.
+func WithRetryMetricsByStatus(collector MetricsCollector) Option {
+	return func(c *Client) error {
+		if collector == nil {
+			return fmt.Errorf("no metrics collector was given")
+		}
+		c.metricsCollector = collector
+		return nil
+	}
+}
+
+// WithBodyReplayPolicy determines the predicate used to check if the body of a request can be replayed in new
+// attempts. Replayable bodies without a GetBody function are buffered in memory, which might not be desired for
+// streaming uploads. Requests with a not replayable body are attempted only once. By default, all bodies are
+// replayable.
+func WithBodyReplayPolicy(policy func(req *http.Request) bool) Option {
+	return func(c *Client) error {
+		c.bodyReplayPolicy = policy
+		return nil
+	}
+}
+
+// WithBodySpillThreshold determines the size, in bytes, above which the request bodies buffered to be replayed are
+// spilled to temporary files instead of memory, keeping large uploads memory-safe. The files are removed once the
+// attempts are over, even if they failed or the context was gone. The bodies with a GetBody function are never
+// buffered. The debug dumps tell only the size of the bodies spilled or larger than the threshold, not reading them.
+func WithBodySpillThreshold(n int64) Option {
+	return func(c *Client) error {
+		if n <= 0 {
+			return fmt.Errorf("body spill threshold must be positive: %d", n)
+		}
+		c.bodySpillThreshold = n
+		return nil
+	}
+}
+
+// WithRequestBodyGzip determines that the replayable request bodies larger than the given min size, in bytes, should
+// be compressed through gzip, along with the Content-Encoding header, as for large JSON uploads to APIs accepting
+// it. The body is compressed once, in memory or, if larger than the body spill threshold given, to a temporary file,
+// so the same compressed body is replayed on each attempt. The bodies already encoded, as told by the
+// Content-Encoding header given or by a Content-Type of archives, images, audios or videos, are not compressed.
+func WithRequestBodyGzip(minSize int64) Option {
+	return func(c *Client) error {
+		if minSize < 0 {
+			return fmt.Errorf("request body gzip min size must not be negative: %d", minSize)
+		}
+		c.gzipRequestBody = true
+		c.gzipMinSize = minSize
+		return nil
+	}
+}
+
+// WithBeforeRequest determines the function called on each attempt right before performing the request, as
+// attaching a fresh bearer token or signing it. Since it is called on a copy of the request, each attempt starts
+// from the original request.
+func WithBeforeRequest(beforeRequest BeforeRequestFunc) Option {
+	return func(c *Client) error {
+		c.beforeRequest = beforeRequest
+		return nil
+	}
+}
+
+// WithRequestSigner determines the RequestSigner used to sign each attempt right before performing it, after all
+// other mutations, as the default headers and the before request hook, so the signatures including timestamps are
+// always fresh. A signing error aborts the attempts.
+func WithRequestSigner(signer RequestSigner) Option {
+	return func(c *Client) error {
+		if signer == nil {
+			return fmt.Errorf("no request signer was given")
+		}
+		c.requestSigner = signer
+		return nil
+	}
+}
+
+// WithSuccessStatusCodes determines the HTTP status codes considered successful. Responses with any other status
+// code are treated as failed attempts, allowing new ones, without calling the ReaderFunc. The status codes given
+// to WithImmediateFallbackOn take precedence over these ones.
+func WithSuccessStatusCodes(statusCodes ...int) Option {
+	return func(c *Client) error {
+		if len(statusCodes) == 0 {
+			return fmt.Errorf("no success status code was given")
+		}
+		if c.successStatusCodes == nil {
+			c.successStatusCodes = make(map[int]struct{}, len(statusCodes))
+		}
+		for i := range statusCodes {
+			c.successStatusCodes[statusCodes[i]] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// WithRetryOn5xx determines that the responses with 5xx HTTP status codes should allow a new attempt regardless of the
+// ReaderFunc, which is not even called for them, so it might focus on reading the successful responses. The
+// ReaderFunc is still called for any other response, and an error returned by it still allows a new attempt.
+func WithRetryOn5xx() Option {
+	return func(c *Client) error {
+		c.retryOn5xx = true
+		return nil
+	}
+}
+
+// WithShouldContinue determines the function called after each failed attempt that would be retried, receiving the
+// number of the failed attempt, its error and its response, which is nil if some transport error occurred and has
+// its body already closed. Returning false stops the attempts immediately, returning the last error as is, allowing
+// dynamic conditions, as a feature flag flipped meanwhile.
+func WithShouldContinue(shouldContinue func(attempt int, lastErr error, lastResp *http.Response) bool) Option {
+	return func(c *Client) error {
+		if shouldContinue == nil {
+			return fmt.Errorf("no should continue function was given")
+		}
+		c.shouldContinue = shouldContinue
+		return nil
+	}
+}
+
+// WithErrorMapper determines the function that maps the errors built by the client before they are returned, as
+// into gRPC statuses or application errors, receiving the error code, its cause, if any, and the last response got,
+// if any, with its body already closed. The errors returned as is from the ReaderFunc, as the ones wrapped by
+// Permanent, as well as the ones returned by the FallbackFunc, are not mapped. Since the callers, as well as some
+// convenience methods, as Do and TryFailover, rely on errors.Is, the mapped errors should keep matching the given error
+// code, as by implementing Is, and wrap the given cause.
+func WithErrorMapper(mapper func(code ErrorCode, cause error, resp *http.Response) error) Option {
+	return func(c *Client) error {
+		if mapper == nil {
+			return fmt.Errorf("no error mapper was given")
+		}
+		c.errorMapper = mapper
+		return nil
+	}
+}
+
+// WithEndpoints determines the weighted endpoints TryBalanced spreads the attempts across, each one picked with a
+// probability proportional to its weight, which must be positive, while the ones with recent failures are less likely
+// to be picked. The base URLs must be unique.
+func WithEndpoints(endpoints []WeightedEndpoint) Option {
+	return func(c *Client) error {
+		if len(endpoints) == 0 {
+			return fmt.Errorf("no endpoints were given")
+		}
+		seen := make(map[string]bool, len(endpoints))
+		for _, endpoint := range endpoints {
+			if endpoint.BaseURL == "" {
+				return fmt.Errorf("no base URL was given for the endpoint")
+			}
+			if endpoint.Weight <= 0 {
+				return fmt.Errorf("invalid weight for endpoint %q: %d", endpoint.BaseURL, endpoint.Weight)
+			}
+			if seen[endpoint.BaseURL] {
+				return fmt.Errorf("duplicated endpoint: %q", endpoint.BaseURL)
+			}
+			seen[endpoint.BaseURL] = true
+		}
+		c.endpoints = append([]WeightedEndpoint(nil), endpoints...)
+		return nil
+	}
+}
+
+// WithRetryIf determines the predicate that decides, after each failed attempt, if a new one should be performed,
+// replacing the default decision, which retries any error not wrapped by Permanent. It receives the response got,
+// with its body already closed, and the error from the transport, or the one returned by the validators or the
+// ReaderFunc, along with the number of the failed attempt, allowing arbitrary logic, as retrying the 503 HTTP status
+// code only on the first attempt while always retrying the 429 one. Returning false stops the attempts immediately,
+// returning the last error as is. The new attempts are still bounded by the max retries, the max elapsed time and the
+// retry budget, while the permanent errors are never retried.
+//
+// The predicate only decides on failed attempts, so the convenience methods, as Do and TryStream, still rely on the
+// RetryPolicy to tell the responses that failed, thus both can't be given, returning an ErrInvalidClientConfiguration.
+func WithRetryIf(retryIf func(resp *http.Response, respErr error, readerErr error, attempt int) bool) Option {
+	return func(c *Client) error {
+		if retryIf == nil {
+			return fmt.Errorf("no retry if function was given")
+		}
+		c.retryIf = retryIf
+		return nil
+	}
+}
+
+// WithRequestRewriter determines the function that should produce the request for each new attempt, allowing
+// adaptive retries, as dropping a problematic header or adding a query parameter after some failure.
+func WithRequestRewriter(rewriter RequestRewriterFunc) Option {
+	return func(c *Client) error {
+		if rewriter == nil {
+			return fmt.Errorf("no request rewriter was given")
+		}
+		c.requestRewriter = rewriter
+		return nil
+	}
+}
+
+// WithOnFallback determines the function called right before the FallbackFunc, receiving the error that triggered
+// it, giving visibility into how often the fallbacks fire, as through metrics or alerts.
+func WithOnFallback(onFallback func(lastErr error)) Option {
+	return func(c *Client) error {
+		if onFallback == nil {
+			return fmt.Errorf("no fallback hook was given")
+		}
+		c.onFallback = onFallback
+		return nil
+	}
+}
+
+// WithOnWarningHeader determines the function called for each Warning, Deprecation or Sunset header value found on
+// any response got, as for logging or alerting about the lifecycle of the APIs called. It doesn't affect the
+// attempts.
+func WithOnWarningHeader(onWarningHeader func(header, value string)) Option {
+	return func(c *Client) error {
+		if onWarningHeader == nil {
+			return fmt.Errorf("no warning header hook was given")
+		}
+		c.onWarningHeader = onWarningHeader
+		return nil
+	}
+}
+
+// WithMaxConcurrency determines how many Try calls might be in flight at once in the client, including their
+// retries. Further calls will wait until some slot is released or their context is gone.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) error {
+		if n <= 0 {
+			return fmt.Errorf("max concurrency must be greater than zero: %d", n)
+		}
+		c.semaphore = make(chan struct{}, n)
+		return nil
+	}
+}
+
+// setUserAgentHeader sets the User-Agent information that will be sent as header, accordingly to RFC7231.
+func (c *Client) setUserAgentHeader() {
+	product := c.productName
+	if c.productVersion != "" {
+		product = fmt.Sprintf("%s/%s", product, c.productVersion)
+	}
+	comment := runtime.Version()
+	if c.withPlatformInfo {
+		comment = fmt.Sprintf("%s; %s/%s", comment, runtime.GOOS, runtime.GOARCH)
+	}
+	userAgentFormatString := "%s (%s)"
+	c.userAgent = fmt.Sprintf(userAgentFormatString, product, comment)
+	if c.userAgentSuffix != "" {
+		c.userAgent = fmt.Sprintf("%s %s", c.userAgent, c.userAgentSuffix)
+	}
+}
+
+// isValidHeaderName checks if the given header name is a valid token, as per RFC 7230, so it is not empty and has
+// neither control characters nor separators, as spaces and colons.
+func isValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", b) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// hasControlCharacters checks if the given header value has control characters, as CR and LF, which might be used
+// to inject additional headers. Horizontal tabs are allowed.
+func hasControlCharacters(value string) bool {
+	for _, r := range value {
+		if (r < ' ' && r != '\t') || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// UserAgent returns the User-Agent header added to the requests.
+func (c *Client) UserAgent() string {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	return c.userAgent
+}
+
+// Name returns the name the client was labeled with, or empty if none was given.
+func (c *Client) Name() string {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	return c.name
+}
+
+// clientNameKey is the context key of the name of the client performing the call.
+type clientNameKey struct{}
+
+// ClientNameFrom gets the name of the client performing the call from the given context, as the ones given to the
+// hooks and carried by the requests attempted, or empty if the client has no name.
+func ClientNameFrom(ctx context.Context) string {
+	name, _ := ctx.Value(clientNameKey{}).(string)
+	return name
+}
+
+// getMaxRetries gets the max retries for the given HTTP status code, bounded by the client max retries, unless they
+// are unlimited.
+func (c *Client) getMaxRetries(statusCode int) int {
+	if n, ok := c.maxRetriesForStatus[statusCode]; ok && (n < c.maxRetries || c.maxRetries == UnlimitedRetries) {
+		return n
+	}
+	return c.maxRetries
+}
+
+// getInterval calculates the interval between each retry based on the given attempt and the client configuration,
+// adding a random jitter.
+func (c *Client) getInterval(waitInterval, maxInterval time.Duration, attempt int, multiplier float64) time.Duration {
+
+	// The first interval grows off the wait interval, while the following ones grow off the backoff base, which is
+	// the wait interval itself if not given, so a single formula applies either way. The attempt given is the retry
+	// plus one.
+	base, exponent := waitInterval, attempt
+	if c.backoffBase > 0 && attempt > 2 {
+		base = c.backoffBase
+	}
+
+	var interval time.Duration
+	if c.jitterFunc != nil {
+		backoff := ComputeBackoff(base, maxInterval, exponent, multiplier, 0)
+		interval = saturatingAdd(backoff, c.jitterFunc(backoff))
+		if maxInterval > 0 && interval > maxInterval {
+			interval = maxInterval
+		}
+		if interval < 0 {
+			interval = 0
+		}
+	} else {
+		jitter, err := c.getJitter()
+		if err != nil {
+			jitter = 0
+		}
+		interval = ComputeBackoff(base, maxInterval, exponent, multiplier, time.Duration(jitter)*time.Millisecond)
+	}
+
+	// The min interval is never greater than the max one, so it can be applied after the clamping.
+	if interval < c.minInterval {
+		interval = c.minInterval
+	}
+	return interval
+}
+
+// getJitter gets a random jitter in milliseconds, from the seeded source if the deterministic mode is enabled.
+func (c *Client) getJitter() (int64, error) {
+	return c.getRandom(1000)
+}
+
+// lockedRand is a seeded random source safe for concurrent use.
+type lockedRand struct {
+	mu   sync.Mutex
+	rand *mathrand.Rand
+}
+
+// int63n gets a random number in [0, n) from the seeded source.
+func (r *lockedRand) int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Int63n(n)
+}
+
+// getRandom gets a random number in [0, n), from the seeded source if the deterministic mode is enabled.
+func (c *Client) getRandom(n int64) (int64, error) {
+	if c.jitterRand != nil {
+		return c.jitterRand.int63n(n), nil
+	}
+	random, err := rand.Int(rand.Reader, big.NewInt(n))
+	if err != nil {
+		return 0, err
+	}
+	return random.Int64(), nil
+}
+
+// isConnectionError checks if the given transport error is a transient connection error, which might succeed
+// in a new attempt. Cancellations and certificate errors are never considered transient, while the transport
+// timeouts are, as long as the request context is still alive, which should be checked by the caller.
+func isConnectionError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if isCertificateError(err) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return false
+}
+
+// isSafeToRetry checks if the given request is safe to be retried after the given transport error. The calls made by
+// the convenience methods, as Do, retry as per DefaultRetryPolicy, so a non-idempotent request, which might have been
+// partially processed unless the connection was refused, is only retried if an Idempotency-Key header was given, or
+// if the connection errors retries were explicitly enabled, or the decision was handed over to a custom RetryPolicy
+// or to the retry predicate given through WithRetryIf. Any other call retries every method.
+func (c *Client) isSafeToRetry(ctx context.Context, req *http.Request, err error) bool {
+	if !methodAwareRetriesFrom(ctx) || c.connectionErrorRetriesEnabled || c.customRetryPolicy || c.retryIf != nil || isIdempotent(req) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// checkRedirect checks if the given redirect should be followed, as per the redirect configurations, given the
+// requests already made, oldest first. Unless bounded by the max redirects, the default HTTP Client policy of
+// stopping after 10 redirects is kept.
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	switch {
+	case !c.followRedirects:
+		return http.ErrUseLastResponse
+	case c.limitRedirects && len(via) > c.maxRedirects:
+		return fmt.Errorf("stopped after %d redirects: %w", c.maxRedirects, ErrTooManyRedirects)
+	case !c.limitRedirects && len(via) >= defaultMaxRedirects:
+		return fmt.Errorf("stopped after %d redirects", defaultMaxRedirects)
+	default:
+		return nil
+	}
+}
+
+// isResponseHeadersTooLargeError checks if the given transport error was caused by response headers exceeding the
+// max size accepted by the transport, which is only reported through its message.
+func isResponseHeadersTooLargeError(err error) bool {
+	return strings.Contains(err.Error(), "server response headers exceeded")
+}
+
+// isCertificateError checks if the given error was caused by an invalid TLS certificate.
+func isCertificateError(err error) bool {
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	return errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.As(err, &hostnameErr)
+}
+
+// Try tries to perform the given request as per configurations. If some FallbackFunc is given,
+// after max retries were reached, it will be called. It might return the following errors:
+//
+// - ErrInvalidClientConfiguration - when the client was not built through NewClient and has no HTTP client.
+//
+// - ErrNoReaderFuncFound - when no reader function was provided.
+//
+// - ErrMaxRetriesReached - if max retries were reached or if the context deadline would be reached while waiting
+// for the next attempt, with the last error got as its cause.
+//
+// - ErrRetryBudgetExhausted - if the retry budget doesn't allow a new attempt, with the last error got as its cause.
+//
+// - ErrContextBudgetExhausted - if the attempt budget attached to the given context through ContextWithBudget
+// doesn't allow a new attempt, with the last error got, if any, as its cause.
+//
+// - ErrBodyNotReplayable - if the attempt failed and the request body can't be replayed, with the error got as its
+// cause.
+//
+// - ErrImmediateFallback - if the response status code requires the fallback, but no FallbackFunc was given.
+//
+// - The error wrapped by Permanent - if the ReaderFunc returned a permanent error.
+//
+// - ErrTimeout or ErrCanceled - if the given context was gone, wrapping context.DeadlineExceeded or
+// context.Canceled, so they might be matched as well.
+//
+// - ErrUnexpected is the error returned when no one of the previous errors match.
+func (c *Client) Try(ctx context.Context, req *http.Request, readerFunc ReaderFunc, fallbackFunc FallbackFunc) error {
+	_, err := c.TryWithResult(ctx, req, readerFunc, fallbackFunc)
+	return err
+}
+
+// TryWithResult tries to perform the given request as Try does, also returning the diagnostics of the attempts
+// performed. The TryResult is empty if the attempts were interrupted because the given context was gone.
+func (c *Client) TryWithResult(ctx context.Context, req *http.Request, readerFunc ReaderFunc, fallbackFunc FallbackFunc) (TryResult, error) {
+	result, err := c.try(ctx, req, nil, readerFunc, fallbackFunc, false)
+	result.response = nil
+	return result, err
+}
+
+// TryResponse tries to perform the given request as Try does, also returning the last response got, if any, even
+// if the attempts failed, so its status code and headers might be inspected, as for logging. Since its body was
+// already closed, it is replaced by http.NoBody, unless the attempts succeeded and WithManualBodyClose was given, in
+// which case the body is left open and MUST be closed by the caller. The response is nil if no response was got or
+// if the attempts were interrupted because the given context was gone.
+func (c *Client) TryResponse(ctx context.Context, req *http.Request, readerFunc ReaderFunc, fallbackFunc FallbackFunc) (*http.Response, error) {
+	result, err := c.try(context.WithValue(ctx, bodyHandedBackKey{}, true), req, nil, readerFunc, fallbackFunc, false)
+	if result.response == nil {
+		return nil, err
+	}
+	resp := *result.response
+	if !result.bodyOpen {
+		resp.Body = http.NoBody
+	}
+	return &resp, err
+}
+
+// bodyHandedBackKey is the context key telling that the body of the accepted response is handed back to the caller,
+// so it might be left open by WithManualBodyClose.
+type bodyHandedBackKey struct{}
+
+// TryCtx tries to perform the given request as Try does, but calling the given FallbackFuncCtx with the given
+// context, so the fallback is bounded by it.
+func (c *Client) TryCtx(ctx context.Context, req *http.Request, readerFunc ReaderFunc, fallbackFunc FallbackFuncCtx) error {
+	var fallback FallbackFunc
+	if fallbackFunc != nil {
+		fallback = func() error {
+			return fallbackFunc(ctx)
+		}
+	}
+	return c.Try(ctx, req, readerFunc, fallback)
+}
+
+// TryFunc returns a closure that tries to perform the given request as Try does once called, suitable for the
+// concurrent orchestration helpers, as errgroup.Group.Go. The given arguments are captured as they were given, so
+// reassigning the variables passed afterwards doesn't affect the closure, which might be safely called later.
+func (c *Client) TryFunc(ctx context.Context, req *http.Request, readerFunc ReaderFunc, fallbackFunc FallbackFunc) func() error {
+	return func() error {
+		return c.Try(ctx, req, readerFunc, fallbackFunc)
+	}
+}
+
+// TryAll tries to perform the given requests concurrently as Try does, each one read by the ReaderFunc returned by
+// the given function for it, and returns the errors got, aligned with the given requests, which are nil for the
+// requests successfully performed. The requests in flight are bounded by WithMaxConcurrency, if given, and stopped
+// once the given context is gone.
+func (c *Client) TryAll(ctx context.Context, requests []*http.Request, readerFor func(req *http.Request) ReaderFunc) []error {
+	errs := make([]error, len(requests))
+	var wg sync.WaitGroup
+	for i := range requests {
+		if requests[i] == nil {
+			errs[i] = newError(ErrUnexpected, withCause(fmt.Errorf("no request was given at index %d", i)))
+			continue
+		}
+		if readerFor == nil {
+			errs[i] = ErrNoReaderFuncFound
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.Try(ctx, requests[i], readerFor(requests[i]), nil)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// TryHead tries to perform a HEAD request to the given URL as per configurations, as for existence or size checks,
+// retrying while the RetryPolicy allows, which by default retries the 5xx and 429 HTTP status codes, and returns the
+// headers and the status code of the last response got, if any, even if the attempts failed. Besides the errors
+// returned by Try, it might return ErrInvalidClientConfiguration if the given URL is not valid.
+func (c *Client) TryHead(ctx context.Context, url string) (http.Header, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, 0, newError(ErrInvalidClientConfiguration, withCause(err))
+	}
+	retryPolicy := c.snapshot().retryPolicy
+	resp, err := c.TryResponse(withMethodAwareRetries(ctx), req, func(response *http.Response) error {
+		if retryPolicy(req, response) {
+			return fmt.Errorf("retriable status code: %s", response.Status)
+		}
+		return nil
+	}, nil)
+	if resp == nil {
+		return nil, 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.Header, resp.StatusCode, err
+}
+
+// TryPreflight tries to perform a CORS preflight OPTIONS request to the given URL as per configurations, asking if
+// the given method is allowed, retrying while the RetryPolicy allows, which by default retries the 5xx and 429 HTTP
+// status codes. The method is allowed if the preflight succeeded with a 2xx HTTP status code listing it, or the *
+// wildcard, in its Access-Control-Allow-Methods header. The Origin header, if required by the server, might be given
+// through WithDefaultHeaders. If the preflight itself failed, it returns ErrPreflightFailed, with the error got as its
+// cause, as ErrMaxRetriesReached, which might be matched as well, or ErrInvalidClientConfiguration if the given URL
+// or method is not valid.
+func (c *Client) TryPreflight(ctx context.Context, url string, method string) (bool, error) {
+	if method == "" {
+		return false, newError(ErrInvalidClientConfiguration, withCause(fmt.Errorf("no method was given")))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodOptions, url, nil)
+	if err != nil {
+		return false, newError(ErrInvalidClientConfiguration, withCause(err))
+	}
+	req.Header.Set(accessControlRequestMethodHeader, method)
+	retryPolicy := c.snapshot().retryPolicy
+	resp, err := c.TryResponse(withMethodAwareRetries(ctx), req, func(response *http.Response) error {
+		if retryPolicy(req, response) {
+			return fmt.Errorf("retriable status code: %s", response.Status)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return false, newError(ErrPreflightFailed, withCause(err))
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return false, nil
+	}
+	for _, value := range resp.Header.Values(accessControlAllowMethodsHeader) {
+		for _, allowed := range strings.Split(value, ",") {
+			if allowed = strings.TrimSpace(allowed); allowed == method || allowed == "*" {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// requestFunc defines the function that provides the request to be performed on the given attempt.
+type requestFunc func(attempt int) (*http.Request, error)
+
+// try tries to perform the given request as per configurations. If some requestFunc is given, it will provide the
+// request for each new attempt instead of replaying the given one. If stream is true, the response body is never
+// read by the client itself, so the ReaderFunc might consume it as it arrives.
+func (c *Client) try(ctx context.Context, req *http.Request, nextRequest requestFunc, readerFunc ReaderFunc, fallbackFunc FallbackFunc, stream bool) (TryResult, error) {
+
+	// The attempts are performed with a snapshot of the configuration.
+	c = c.snapshot()
+
+	// The context carries the name of the client, replacing the one of any outer call, so the hooks might use it.
+	ctx = context.WithValue(ctx, clientNameKey{}, c.name)
+
+	// Checks if the client was properly built, avoiding a nil pointer dereference while sending the request
+	if c.httpClient == nil {
+		return TryResult{}, c.mapError(newError(ErrInvalidClientConfiguration, withCause(fmt.Errorf("%w: the client must be created through NewClient", ErrNoHTTPClientFound))), nil)
+	}
+
+	// Checks if a reader function was given
+	if readerFunc == nil {
+		return TryResult{}, c.mapError(ErrNoReaderFuncFound, nil)
+	}
+
+	// Checks if the retries are bounded by something, otherwise they would never end
+	if c.manualBodyClose {
+		if handedBack, _ := ctx.Value(bodyHandedBackKey{}).(bool); !handedBack {
+			return TryResult{}, c.mapError(newError(ErrInvalidClientConfiguration, withCause(fmt.Errorf("manual body close can only be used along with Do or TryResponse, which hand the response body back to the caller"))), nil)
+		}
+	}
+
+	if c.maxRetries == UnlimitedRetries && c.maxElapsedTime == 0 && ctx.Done() == nil {
+		return TryResult{}, c.mapError(newError(ErrInvalidClientConfiguration, withCause(fmt.Errorf("unlimited retries require a max elapsed time or a cancelable context"))), nil)
+	}
+
+	// Checks if the attempt budget attached to the context, if any, allows the first attempt, failing fast otherwise
+	budget := contextBudgetFrom(ctx)
+	if budget != nil && !budget.debit() {
+		err := newError(ErrContextBudgetExhausted, withCause(fmt.Errorf("no attempt left in the context budget")))
+		if fallbackFunc != nil {
+			return TryResult{}, c.fallback(err, fallbackFunc)
+		}
+		return TryResult{}, c.mapError(err, nil)
+	}
+
+	// Waits for a free slot if the concurrency is bounded
+	if c.semaphore != nil {
+		select {
+		case c.semaphore <- struct{}{}:
+			defer func() { <-c.semaphore }()
+		case <-ctx.Done():
+			return TryResult{}, c.mapError(newContextError(ctx.Err()), nil)
+		}
+	}
+
+	// Sets the User-Agent header if asked
+	c.addUserAgentHeader(ctx, req)
+
+	// Create the channel to receive the outcome of the attempts.
+	outcomes := make(chan attemptOutcome, 1)
+
+	// Sends the request. The result is only filled by sendRequest before sending its outcome.
+	// The request bodies spilled to temporary files are removed as soon as the call is over.
+	result := &TryResult{}
+	spilled := &spillFiles{}
+	defer c.removeSpilledBodies(ctx, spilled)
+	go c.sendRequest(ctx, req, nextRequest, readerFunc, stream, result, spilled, outcomes)
+
+	// Listen to the channel previously created or some signaling from the given context.
+	select {
+	case outcome := <-outcomes:
+		result.response = outcome.resp
+		if outcome.err == nil {
+			result.bodyOpen = c.manualBodyClose
+			return *result, nil
+		}
+		c.removeSpilledBodies(ctx, spilled)
+		if fallbackFunc != nil {
+			return *result, c.fallback(outcome.err, fallbackFunc)
+		}
+		return *result, c.mapError(outcome.err, outcome.resp)
+	case <-ctx.Done():
+		return TryResult{}, c.mapError(newContextError(ctx.Err()), nil)
+	}
+}
+
+// attemptOutcome holds the outcome of the attempts performed by sendRequest, which is nil error if the request was
+// successfully performed, along with the last response got, if any, with its body already closed.
+type attemptOutcome struct {
+	err  error
+	resp *http.Response
+}
+
+// mapError maps the given error through the error mapper, if given, along with the last response got, if any. Only
+// the errors built by the client are mapped, so the ones returned by the ReaderFunc as is are kept.
+func (c *Client) mapError(err error, resp *http.Response) error {
+	if c.errorMapper == nil {
+		return err
+	}
+	switch e := err.(type) {
+	case Error:
+		return c.errorMapper(e.ErrorCode, e.cause, resp)
+	case ErrorCode:
+		return c.errorMapper(e, nil, resp)
+	default:
+		return err
+	}
+}
+
+// fallback calls the given FallbackFunc due to the given error, notifying the fallback hook, if given.
+func (c *Client) fallback(err error, fallbackFunc FallbackFunc) error {
+	if c.onFallback != nil {
+		c.onFallback(err)
+	}
+	return fallbackFunc()
+}
+
+// removeSpilledBodies removes the temporary files the request bodies were spilled to, printing any error if the debug
+// is enabled.
+func (c *Client) removeSpilledBodies(ctx context.Context, spilled *spillFiles) {
+	if err := spilled.remove(); err != nil && c.isDebugEnabled(ctx) {
+		c.debugPrintln(err)
+	}
+}
+
+// addUserAgentHeader adds the User-Agent header to the given request if asked.
+func (c *Client) addUserAgentHeader(ctx context.Context, req *http.Request) {
+	if c.withUserAgentHeader {
+		req.Header.Add(userAgentHeader, c.userAgent)
+		return
+	}
+	if c.isDebugEnabled(ctx) {
+		if v := req.Header.Get(userAgentHeader); v == "" {
+			c.debugPrintln("no User-Agent was given")
+		}
+	}
+}
+
+// Do sends the given request as per configurations, mirroring http.Client.Do, but retrying while the RetryPolicy
+// allows, which by default retries the 5xx and 429 HTTP status codes of idempotent requests. The last response got is
+// returned, even if the retries ended due to max retries, the retry budget, the context budget, the max elapsed time
+// or the ShouldContinue function, and as in http.Client.Do, the caller is responsible for closing its body. The
+// request context is used to perform the request.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+
+	// The RetryPolicy is taken from a snapshot of the configuration, as the one the attempts are performed with.
+	retryPolicy := c.snapshot().retryPolicy
+
+	// Holds the last response got and its body, which shouldn't be closed automatically.
+	var mu sync.Mutex
+	var lastResp *http.Response
+	var lastBody io.ReadCloser
+	var retryErr error
+	done := false
+
+	readerFunc := func(response *http.Response) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Try already returned, so no one will read this response.
+		if done {
+			return nil
+		}
+
+		// Discards the previous response, since a new one was got.
+		if lastBody != nil {
+			_ = lastBody.Close()
+		}
+		lastResp, lastBody = response, response.Body
+		response.Body = io.NopCloser(lastBody)
+
+		retryErr = nil
+		if retryPolicy(req, response) {
+			retryErr = fmt.Errorf("retriable status code: %s", response.Status)
+		}
+		return retryErr
+	}
+
+	err := c.Try(context.WithValue(withMethodAwareRetries(req.Context()), bodyHandedBackKey{}, true), req, readerFunc, nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	done = true
+
+	// The retries ended due to the last response if the error wraps the one it was retried for, whichever the reason
+	// no new attempt was performed, so it is returned as per http.Client.Do.
+	if lastResp == nil || (err != nil && (retryErr == nil || !errors.Is(err, retryErr))) {
+		if lastBody != nil {
+			_ = lastBody.Close()
+		}
+		return nil, err
+	}
+	lastResp.Body = lastBody
+	return lastResp, nil
+}
+
+// sendRequest Sends the given request calling the given ReaderFunc to parse and analyse its return. If some
+// requestFunc is given, it will provide the request for each new attempt. The outcome of the attempts is
+// communicated via the given channel, which must be able to buffer it, unless the context was gone meanwhile.
+func (c *Client) sendRequest(ctx context.Context, req *http.Request, nextRequest requestFunc, readerFunc ReaderFunc, stream bool, result *TryResult, spilled *spillFiles, outcomes chan<- attemptOutcome) {
 
-	// Listen to the channels previously created or some signaling from the given context.
-	select {
-	case err := <-errChan:
-		if fallbackFunc != nil {
-			return fallbackFunc()
+	// Sends out the outcome of the attempts along with the last response got, if any.
+	var gotResp *http.Response
+	sendOutcome := func(err error) {
+		outcomes <- attemptOutcome{err: err, resp: gotResp}
+	}
+
+	// Checks if the request body can be replayed in new attempts, buffering it if needed. The bodies spilled to
+	// temporary files are removed once the attempts are over, if not removed already.
+	defer c.removeSpilledBodies(ctx, spilled)
+	replayable, err := c.prepareBody(ctx, req, spilled)
+	if err != nil {
+		sendOutcome(newError(ErrUnexpected, withCause(err)))
+		return
+	}
+
+	// Computes the idempotency key of the request, if asked, which is kept across all attempts.
+	idempotencyKey := ""
+	if c.idempotencyKeyHeader != "" && replayable {
+		if idempotencyKey, err = getIdempotencyKey(req); err != nil {
+			sendOutcome(newError(ErrUnexpected, withCause(err)))
+			return
 		}
-		return err
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-resultChan:
-		return nil
+		c.addIdempotencyKeyHeader(req, idempotencyKey)
 	}
-}
 
-// sendRequest Sends the given request calling the given ReaderFunc to parse and analyse its return. Both, errors
-// results are communicated via channels.
-func (c *Client) sendRequest(ctx context.Context, req *http.Request, readerFunc ReaderFunc, errChan chan<- error, resultChan chan<- struct{}) {
+	// Compresses the request body, if asked.
+	if err := c.prepareBodyEncoding(ctx, req, replayable, spilled); err != nil {
+		sendOutcome(newError(ErrUnexpected, withCause(err)))
+		return
+	}
 
-	// Attempts counter
+	// Attempts counter, the last response and error got, if any, and when the attempts started.
 	attempt := 0
+	var lastResp *http.Response
+	var lastErr error
+	start := c.clock.Now()
 
 	// Will iterate until max retries were reached or the request was successfully performed.
 	for {
 
-		// Dumps the request if the debug is enabled
-		if c.debug {
-			b, err := httputil.DumpRequest(req, true)
-			if err != nil {
-				errChan <- newError(ErrUnexpected, withCause(err))
+		// Gets the request for the new attempt, if they are provided per attempt.
+		if attempt > 0 && nextRequest != nil {
+			if req, err = nextRequest(attempt); err != nil {
+				sendOutcome(newError(ErrUnexpected, withCause(err)))
+				return
+			}
+			c.addUserAgentHeader(ctx, req)
+			c.addIdempotencyKeyHeader(req, idempotencyKey)
+			if replayable, err = c.prepareBody(ctx, req, spilled); err != nil {
+				sendOutcome(newError(ErrUnexpected, withCause(err)))
+				return
+			}
+			if err = c.prepareBodyEncoding(ctx, req, replayable, spilled); err != nil {
+				sendOutcome(newError(ErrUnexpected, withCause(err)))
 				return
 			}
-			c.debugger.Println(string(b))
 		}
 
-		// Clone the request to avoid reading twice
-		clonedReq := req.Clone(ctx)
-		if req.Body != nil {
-			clonedBody, err := req.GetBody()
-			if err != nil {
-				errChan <- newError(ErrUnexpected, withCause(err))
+		// Rewrites the request for the new attempt, if a rewriter was given, which must keep its body replayable.
+		if attempt > 0 && c.requestRewriter != nil {
+			if rewritten := c.requestRewriter(req, lastResp, attempt); rewritten != nil && rewritten != req {
+				req = rewritten
+				if req.Header.Get(userAgentHeader) == "" {
+					c.addUserAgentHeader(ctx, req)
+				}
+				c.addIdempotencyKeyHeader(req, idempotencyKey)
+				if replayable, err = c.prepareBody(ctx, req, spilled); err != nil {
+					sendOutcome(newError(ErrUnexpected, withCause(err)))
+					return
+				}
+				if !replayable {
+					sendOutcome(newError(ErrBodyNotReplayable, withCause(fmt.Errorf("the request rewritten for attempt %d has a not replayable body", attempt+1))))
+					return
+				}
+				if err = c.prepareBodyEncoding(ctx, req, replayable, spilled); err != nil {
+					sendOutcome(newError(ErrUnexpected, withCause(err)))
+					return
+				}
 			}
-			clonedReq.Body = clonedBody
 		}
 
-		// Perform the request
-		resp, err := c.httpClient.Do(clonedReq)
+		// Performs the attempt and if some error was returned, will allow a new attempt, unless it is permanent.
+		// Gets the timeout of the attempt, bounded by the remaining elapsed time, which must be worth an attempt.
+		timeout := c.attemptTimeout
+		if c.maxElapsedTime > 0 {
+			remaining := c.maxElapsedTime - c.clock.Now().Sub(start)
+			if remaining < minAttemptTime {
+				cause := fmt.Errorf("no time left for attempt %d within %v", attempt+1, c.maxElapsedTime)
+				if lastErr != nil {
+					cause = fmt.Errorf("%v: %w", cause, lastErr)
+				}
+				sendOutcome(newError(ErrMaxElapsedTimeReached, withCause(cause)))
+				return
+			}
+			if timeout == 0 || remaining < timeout {
+				timeout = remaining
+			}
+		}
 
-		// If some unexpected error occurred
-		if err != nil {
-			errChan <- newError(ErrUnexpected, withCause(fmt.Errorf("unexpected error during attempt %d: %w", attempt+1, err)))
-			return
+		resp, err := c.performAttempt(ctx, req, replayable, attempt, timeout, readerFunc, stream, result)
+		lastResp, lastErr = resp, err
+		if resp != nil {
+			gotResp = resp
 		}
 
-		// Dumps the response if the debug is enabled
-		if c.debug {
-			b, err := httputil.DumpResponse(resp, true)
-			if err != nil {
-				errChan <- newError(ErrUnexpected, withCause(err))
+		// If no error, send out the result.
+		if err == nil {
+			if c.retryBudget != nil {
+				c.retryBudget.deposit()
 			}
-			c.debugger.Println(string(b))
+			sendOutcome(nil)
+			return
+		}
+
+		// If the error is permanent, no new attempt is allowed.
+		if permanentErr, ok := asPermanent(err); ok {
+			sendOutcome(permanentErr)
+			return
 		}
 
-		// Call provided ReaderFunc and if some error was returned, will allow a new attempt.
-		err = readerFunc(resp)
+		// Print the given error from the ReaderFunc or the transport if the debug is enabled.
+		if c.isDebugEnabled(ctx) {
+			c.debugPrintln(fmt.Errorf("attempt %d: %w", attempt+1, err).Error() + c.getRetryLogFields(ctx))
+		}
 
-		// Closes the response body just in case the reader function forgot to do so.
-		func(Body io.ReadCloser) {
-			if closeErr := Body.Close(); closeErr != nil {
-				if c.debug {
-					c.debugger.Println(fmt.Errorf("error while closing response body: %w", closeErr))
-				}
+		// Increase the attempts counter and check its limit.
+		attempt++
+		if !replayable && nextRequest == nil {
+			sendOutcome(newError(ErrBodyNotReplayable, withCause(err)))
+			return
+		}
+		if c.retryIf != nil && !c.shouldRetryIf(lastResp, err, attempt) {
+			sendOutcome(err)
+			return
+		}
+		if maxRetries := c.getMaxRetries(statusCodeOf(lastResp)); maxRetries != UnlimitedRetries && attempt >= maxRetries {
+			if !c.waitExhaustionJitter(ctx) {
+				return
+			}
+			if IsConnectivityError(err) {
+				err = fmt.Errorf("server unreachable after %d attempts: %w", attempt, err)
 			}
-		}(resp.Body)
+			sendOutcome(newError(ErrMaxRetriesReached, withCause(err)))
+			return
+		}
 
-		// If no error, send out the result.
-		if err == nil {
-			resultChan <- struct{}{}
+		// Checks if the caller allows a new attempt.
+		if c.shouldContinue != nil && !c.shouldContinue(attempt, err, lastResp) {
+			sendOutcome(err)
 			return
 		}
 
-		// Print the given error from the ReaderFunc if the debug is enabled.
-		if c.debug {
-			c.debugger.Println(fmt.Errorf("attempt %d: %w", attempt+1, err))
+		// Checks if the attempt budget attached to the context, if any, allows a new attempt.
+		if budget := contextBudgetFrom(ctx); budget != nil && !budget.debit() {
+			sendOutcome(newError(ErrContextBudgetExhausted, withCause(err)))
+			return
 		}
 
-		// Increase the attempts counter and check its limit.
-		attempt++
-		if attempt == c.maxRetries {
-			errChan <- ErrMaxRetriesReached
+		// Checks if the retry budget allows a new attempt.
+		if c.retryBudget != nil && !c.retryBudget.withdraw() {
+			sendOutcome(newError(ErrRetryBudgetExhausted, withCause(err)))
+			return
+		}
+
+		// Wait for the next iteration using exponential backoff and jitter, unless the context deadline would be
+		// reached before the next attempt, which would be a guaranteed timeout.
+		interval := c.getInterval(c.waitInterval, c.maxInterval, attempt+1, c.multiplier)
+		if c.serviceUnavailableBackoff > 0 && interval < c.serviceUnavailableBackoff && lastResp != nil &&
+			lastResp.StatusCode == http.StatusServiceUnavailable && lastResp.Header.Get(retryAfterHeader) == "" {
+			interval = c.serviceUnavailableBackoff
+		}
+		// Honors the reconnection interval given by the server-sent event stream being read, if any.
+		if reconnect := sseReconnectIntervalFrom(ctx); reconnect > 0 {
+			interval = reconnect
+		}
+		if c.maxElapsedTime > 0 && interval >= c.maxElapsedTime-c.clock.Now().Sub(start) {
+			sendOutcome(newError(ErrMaxElapsedTimeReached, withCause(fmt.Errorf("no time left for attempt %d within %v: %w", attempt+1, c.maxElapsedTime, err))))
 			return
 		}
+		// The context deadline is bound to the real time, whatever the Clock given.
+		if deadline, ok := ctx.Deadline(); ok && interval >= time.Until(deadline) {
+			sendOutcome(newError(ErrMaxRetriesReached, withCause(fmt.Errorf("no time left for attempt %d before the context deadline: %w", attempt+1, err))))
+			return
+		}
+		if len(result.Intervals) < maxRecordedIntervals {
+			result.Intervals = append(result.Intervals, interval)
+		}
+		if named, ok := c.metricsCollector.(NamedMetricsCollector); ok {
+			named.IncRetryForStatusNamed(c.name, statusCodeOf(lastResp))
+		} else if c.metricsCollector != nil {
+			c.metricsCollector.IncRetryForStatus(statusCodeOf(lastResp))
+		}
+		select {
+		case <-c.clock.After(interval):
+		case <-ctx.Done():
+			sendOutcome(newContextError(ctx.Err()))
+			return
+		}
+	}
+}
+
+// statusCodeOf gets the HTTP status code of the given response, or 0 if no response was got.
+func statusCodeOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// shouldRetryIf calls the retry predicate for the given failed attempt, telling the transport errors, which have no
+// response, from the ones returned by the validators or the ReaderFunc.
+func (c *Client) shouldRetryIf(resp *http.Response, err error, attempt int) bool {
+	if resp == nil {
+		return c.retryIf(nil, err, nil, attempt)
+	}
+	return c.retryIf(resp, nil, err, attempt)
+}
+
+// waitExhaustionJitter waits a random duration up to the exhaustion jitter, if given, returning false if the given
+// context was gone meanwhile.
+func (c *Client) waitExhaustionJitter(ctx context.Context) bool {
+	if c.exhaustionJitter <= 0 {
+		return true
+	}
+	wait, err := c.getRandom(int64(c.exhaustionJitter))
+	if err != nil {
+		return true
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline) - exhaustionJitterMargin
+		if remaining <= 0 {
+			return true
+		}
+		if time.Duration(wait) > remaining {
+			wait = int64(remaining)
+		}
+	}
+	select {
+	case <-c.clock.After(time.Duration(wait)):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// performAttempt performs a single attempt of the given request, calling the given ReaderFunc to parse and analyse
+// its return. It returns the response got, if any, with its body already closed, and nil if the attempt succeeded
+// or an error otherwise, which will be wrapped by Permanent if no new attempt should be performed. If the given timeout
+// is positive, the attempt is bounded by it, while if stream is true, the response body is left to the ReaderFunc.
+func (c *Client) performAttempt(ctx context.Context, req *http.Request, replayable bool, attempt int, timeout time.Duration, readerFunc ReaderFunc, stream bool, result *TryResult) (*http.Response, error) {
+
+	// Bounds the attempt by the given timeout, if any.
+	attemptCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Clone the request to avoid reading twice
+	clonedReq := req.Clone(attemptCtx)
+	if req.Body != nil && replayable {
+		clonedBody, err := req.GetBody()
+		if err != nil {
+			return nil, Permanent(newError(ErrUnexpected, withCause(err)))
+		}
+		clonedReq.Body = clonedBody
+	}
+
+	// Asks the server to confirm the request before its body is sent, if enabled.
+	if c.expectContinue && clonedReq.Body != nil && clonedReq.Body != http.NoBody && clonedReq.Header.Get(expectHeader) == "" {
+		clonedReq.Header.Set(expectHeader, "100-continue")
+	}
+
+	// Adds the headers from the context not set by the caller.
+	if c.headersFromContext != nil {
+		c.addHeadersFromContext(ctx, clonedReq)
+	}
+
+	// Adds the default headers not set by the caller.
+	if clonedReq.Header == nil && len(c.defaultHeaders) > 0 {
+		clonedReq.Header = make(http.Header, len(c.defaultHeaders))
+	}
+	for key, values := range c.defaultHeaders {
+		if _, ok := clonedReq.Header[key]; !ok {
+			clonedReq.Header[key] = append([]string(nil), values...)
+		}
+	}
+
+	// Propagates the trace context, if asked, unless already set by the caller.
+	if c.traceContext != nil {
+		traceparent, tracestate := c.traceContext(ctx)
+		setHeaderIfAbsent(clonedReq, traceparentHeader, traceparent)
+		setHeaderIfAbsent(clonedReq, tracestateHeader, tracestate)
+	}
+
+	// Strips the hop-by-hop headers, unless asked to keep them.
+	if !c.keepHopByHopHeaders {
+		removeHopByHopHeaders(clonedReq)
+	}
+
+	// Calls the before request hook, if given, allowing the request to be mutated. Only ErrRetryRequest allows a
+	// new attempt.
+	if c.beforeRequest != nil {
+		if err := c.beforeRequest(attemptCtx, clonedReq); err != nil {
+			if errors.Is(err, ErrRetryRequest) {
+				return nil, fmt.Errorf("before request hook asked for a new attempt: %w", err)
+			}
+			return nil, Permanent(newError(ErrUnexpected, withCause(fmt.Errorf("before request hook failed during attempt %d: %w", attempt+1, err))))
+		}
+	}
+
+	// Signs the request as it will be sent, if a signer was given.
+	if c.requestSigner != nil {
+		if err := c.requestSigner.Sign(clonedReq); err != nil {
+			return nil, Permanent(newError(ErrUnexpected, withCause(fmt.Errorf("request signing failed during attempt %d: %w", attempt+1, err))))
+		}
+	}
+
+	// Dumps the request as it will be sent if the debug is enabled, without reading a not replayable body, nor a large
+	// one, which would be read into memory, telling only its size instead.
+	if c.isDebugEnabled(ctx) {
+		size, large := c.largeBodySize(clonedReq)
+		b, err := httputil.DumpRequest(clonedReq, replayable && !large)
+		if err != nil {
+			return nil, Permanent(newError(ErrUnexpected, withCause(err)))
+		}
+		c.dump(b)
+		if large {
+			c.debugPrintln(fmt.Sprintf("attempt %d: request body of %d bytes not dumped", attempt+1, size))
+		}
+	}
+
+	// Perform the request, timing it apart from the reading of the response.
+	result.Attempts = attempt + 1
+	doStart := c.clock.Now()
+	resp, err := c.httpClient.Do(clonedReq)
+	if c.isDebugEnabled(ctx) {
+		doDuration := c.clock.Now().Sub(doStart)
+		if err != nil {
+			c.debugPrintln(fmt.Sprintf("attempt %d: request failed in %v", attempt+1, doDuration) + c.getRetryLogFields(ctx))
+		} else {
+			c.debugPrintln(fmt.Sprintf("attempt %d: %s in %v", attempt+1, resp.Status, doDuration) + c.getRetryLogFields(ctx))
+		}
+	}
+
+	// If some transport error occurred, only connection errors might allow a new attempt, if enabled and if the
+	// request context is still alive, as well as the attempt timeout, as long as the request is safe to be retried.
+	if err != nil {
+		if ctx.Err() == nil && attemptCtx.Err() != nil && c.isSafeToRetry(ctx, clonedReq, err) {
+			return nil, fmt.Errorf("attempt %d timed out: %w", attempt+1, err)
+		}
+		if errors.Is(err, ErrTooManyRedirects) {
+			return nil, Permanent(newError(ErrTooManyRedirects, withCause(fmt.Errorf("too many redirects during attempt %d: %w", attempt+1, err))))
+		}
+		if isResponseHeadersTooLargeError(err) {
+			return nil, Permanent(newError(ErrResponseHeadersTooLarge, withCause(fmt.Errorf("response headers too large during attempt %d: %w", attempt+1, err))))
+		}
+		if !c.retryOnConnectionErrors || ctx.Err() != nil || !isConnectionError(err) || !c.isSafeToRetry(ctx, clonedReq, err) {
+			return nil, Permanent(newError(ErrUnexpected, withCause(fmt.Errorf("unexpected error during attempt %d: %w", attempt+1, err))))
+		}
+		return nil, err
+	}
+	// Some misbehaving transports return responses without a body, which is then considered empty.
+	if resp.Body == nil {
+		if c.isDebugEnabled(ctx) {
+			c.debugPrintln(fmt.Sprintf("warning: attempt %d got a response without a body", attempt+1))
+		}
+		resp.Body = http.NoBody
+	}
+	result.StatusCode = resp.StatusCode
+	c.notifyWarningHeaders(resp)
+
+	// Preloads the response body, if asked, before dumping it, so the ReaderFunc might read it multiple times.
+	var preloaded []byte
+	preload := c.preloadResponseBody && !stream
+	if preload {
+		preloaded, err = c.preloadBody(ctx, resp)
+		result.BytesRead = int64(len(preloaded))
+		if err != nil {
+			return resp, err
+		}
+	}
+
+	// Dumps the response if the debug is enabled, without reading a streamed body.
+	if c.isDebugEnabled(ctx) {
+		b, err := httputil.DumpResponse(resp, !stream)
+		if err != nil {
+			c.closeResponseBody(ctx, resp)
+			return resp, Permanent(newError(ErrUnexpected, withCause(err)))
+		}
+		c.dump(b)
+	}
+	if preload {
+		resp.Body = &preloadedBody{Reader: bytes.NewReader(preloaded)}
+	}
+
+	// If the status code requires the fallback, no reading nor new attempt is performed.
+	if _, ok := c.immediateFallbackStatusCodes[resp.StatusCode]; ok {
+		c.closeResponseBody(ctx, resp)
+		return resp, Permanent(newError(ErrImmediateFallback, withCause(fmt.Errorf("status code %d requires the fallback", resp.StatusCode))))
+	}
+
+	// If the server rejected the Expect: 100-continue header, no new attempt is performed.
+	if c.expectContinue && resp.StatusCode == http.StatusExpectationFailed {
+		c.closeResponseBody(ctx, resp)
+		return resp, Permanent(newError(ErrExpectationFailed, withCause(fmt.Errorf("the server rejected the expectation during attempt %d", attempt+1))))
+	}
+
+	// Counts the bytes read from the response body, unless it was preloaded, keeping it seekable.
+	var body *countingReadCloser
+	if !preload {
+		body = &countingReadCloser{ReadCloser: resp.Body}
+		resp.Body = body
+	}
+
+	// Handle the response calling the provided ReaderFunc and if some error was returned, will allow a new attempt.
+	err = c.handleResponse(ctx, resp, readerFunc, stream)
+
+	// Closes the response body just in case the reader function forgot to do so, unless it owns the body of a
+	// successful attempt.
+	if err != nil || !c.manualBodyClose {
+		c.closeResponseBody(ctx, resp)
+	}
+	if body != nil {
+		result.BytesRead = body.count()
+	}
+
+	return resp, err
+}
+
+// notifyWarningHeaders calls the warning header hook, if given, for each warning, deprecation or sunset header value
+// of the given response.
+func (c *Client) notifyWarningHeaders(resp *http.Response) {
+	if c.onWarningHeader == nil {
+		return
+	}
+	for _, header := range []string{warningHeader, deprecationHeader, sunsetHeader} {
+		for _, value := range resp.Header.Values(header) {
+			c.onWarningHeader(header, value)
+		}
+	}
+}
+
+// prepareBody checks if the body of the given request can be replayed in new attempts, as per the body replay
+// policy. If so and the request has no GetBody function, the body is buffered in memory, unless it is larger than the
+// body spill threshold, if given, in which case it is spilled to a temporary file tracked by the given spillFiles.
+func (c *Client) prepareBody(ctx context.Context, req *http.Request, spilled *spillFiles) (bool, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true, nil
+	}
+	if c.bodyReplayPolicy != nil && !c.bodyReplayPolicy(req) {
+		return false, nil
+	}
+	if req.GetBody != nil {
+		return true, nil
+	}
+	original := req.Body
+	reader := io.Reader(original)
+	if c.bodySpillThreshold > 0 {
+		reader = io.LimitReader(original, c.bodySpillThreshold+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		err = fmt.Errorf("error while buffering request body: %w", err)
+	}
+	spill := err == nil && c.bodySpillThreshold > 0 && int64(len(body)) > c.bodySpillThreshold
+	if spill {
+		err = spilled.spillBody(req, body, original)
+	}
+	if closeErr := original.Close(); closeErr != nil && c.isDebugEnabled(ctx) {
+		c.debugPrintln(fmt.Errorf("error while closing request body: %w", closeErr))
+	}
+	if err != nil {
+		return false, err
+	}
+	if spill {
+		return true, nil
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return true, nil
+}
+
+// prepareBodyEncoding compresses the body of the given request, if asked and if it is replayable, spilling it to a
+// temporary file tracked by the given spillFiles if it is larger than the body spill threshold.
+func (c *Client) prepareBodyEncoding(ctx context.Context, req *http.Request, replayable bool, spilled *spillFiles) error {
+	if !c.gzipRequestBody || !replayable {
+		return nil
+	}
+	return c.compressBody(ctx, req, spilled)
+}
+
+// setHeaderIfAbsent sets the given header value to the given request, unless it is empty, has control characters or
+// the header was already set.
+func setHeaderIfAbsent(req *http.Request, key, value string) {
+	if value == "" || hasControlCharacters(value) || req.Header.Get(key) != "" {
+		return
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Set(key, value)
+}
+
+// addHeadersFromContext adds the headers provided from the given context to the given request, unless already set,
+// dropping the invalid ones.
+func (c *Client) addHeadersFromContext(ctx context.Context, req *http.Request) {
+	for key, values := range c.headersFromContext(ctx) {
+		if !isValidHeaderName(key) {
+			if c.isDebugEnabled(ctx) {
+				c.debugPrintln(fmt.Sprintf("warning: invalid header name %q from context was dropped", key))
+			}
+			continue
+		}
+		key = http.CanonicalHeaderKey(key)
+		if _, ok := req.Header[key]; ok {
+			continue
+		}
+		valid := make([]string, 0, len(values))
+		for i := range values {
+			if hasControlCharacters(values[i]) {
+				if c.isDebugEnabled(ctx) {
+					c.debugPrintln(fmt.Sprintf("warning: invalid header %s value %q from context was dropped", key, values[i]))
+				}
+				continue
+			}
+			valid = append(valid, values[i])
+		}
+		if len(valid) == 0 {
+			continue
+		}
+		if req.Header == nil {
+			req.Header = make(http.Header)
+		}
+		req.Header[key] = valid
+	}
+}
+
+// removeHopByHopHeaders removes the hop-by-hop headers from the given request, including the ones listed by its
+// Connection header, which still closes the connection after the request or asks for the upgrade, if asked.
+func removeHopByHopHeaders(req *http.Request) {
+	upgrade := false
+	for _, value := range req.Header.Values(connectionHeader) {
+		for _, name := range strings.Split(value, ",") {
+			switch name = strings.TrimSpace(name); {
+			case strings.EqualFold(name, "close"):
+				req.Close = true
+			case strings.EqualFold(name, upgradeHeader):
+				upgrade = true
+			case name != "":
+				req.Header.Del(name)
+			}
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		req.Header.Del(name)
+	}
+
+	// The upgrade requests keep asking for the protocol switch, which is meant to reach the server.
+	if upgrade {
+		req.Header.Set(connectionHeader, upgradeHeader)
+	}
+}
 
-		// Wait for the next iteration using exponential backoff and jitter
-		retryTimer := time.NewTimer(c.getInterval(c.waitInterval, c.maxInterval, attempt+1, c.multiplier))
-		<-retryTimer.C
+// getIdempotencyKey computes the idempotency key of the given request as the hex encoded SHA-256 of its method, URL
+// and body, which must be replayable.
+func getIdempotencyKey(req *http.Request) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + "\n" + req.URL.String() + "\n"))
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", fmt.Errorf("error while hashing request body: %w", err)
+		}
+		defer body.Close()
+		if _, err := io.Copy(hash, body); err != nil {
+			return "", fmt.Errorf("error while hashing request body: %w", err)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// addIdempotencyKeyHeader sets the given idempotency key to the given request, unless it was not computed or the
+// header was already set.
+func (c *Client) addIdempotencyKeyHeader(req *http.Request, idempotencyKey string) {
+	setHeaderIfAbsent(req, c.idempotencyKeyHeader, idempotencyKey)
+}
+
+// dump writes the given request or response dump to the debug writer, if given, or to the Debugger otherwise.
+func (c *Client) dump(b []byte) {
+	b = truncateDump(b, c.debugBodyLimit)
+	if c.debugWriter != nil {
+		if _, err := c.debugWriter.Write(b); err != nil {
+			c.debugPrintln(fmt.Errorf("error while writing dump: %w", err))
+		}
+		return
+	}
+	c.debugPrintln(string(b))
+}
+
+// debugPrintln prints the given message through the Debugger, prefixed with the client name, if given.
+func (c *Client) debugPrintln(v any) {
+	if c.name == "" {
+		c.debugger.Println(v)
+		return
+	}
+	c.debugger.Println(fmt.Sprintf("[%s] %v", c.name, v))
+}
+
+// isDebugEnabled checks if the attempts of the call with the given context should be debugged.
+func (c *Client) isDebugEnabled(ctx context.Context) bool {
+	if c.debugFromContext != nil {
+		return c.debugFromContext(ctx)
+	}
+	return c.debug
+}
+
+// getRetryLogFields gets the fields provided for the given context as key=value pairs sorted by key, each one
+// preceded by a space, or an empty string if no fields were provided.
+func (c *Client) getRetryLogFields(ctx context.Context) string {
+	if c.retryLogFields == nil {
+		return ""
+	}
+	fields := c.retryLogFields(ctx)
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&sb, " %s=%v", key, fields[key])
+	}
+	return sb.String()
+}
+
+// truncateDump truncates the body of the given request or response dump to the given limit, appending a marker
+// with the total body size. A limit of 0 means no truncation.
+func truncateDump(b []byte, limit int) []byte {
+	if limit == 0 {
+		return b
+	}
+	separator := []byte("\r\n\r\n")
+	i := bytes.Index(b, separator)
+	if i < 0 {
+		return b
+	}
+	headers, body := b[:i+len(separator)], b[i+len(separator):]
+	if len(body) <= limit {
+		return b
+	}
+	truncated := make([]byte, 0, len(headers)+limit+64)
+	truncated = append(truncated, headers...)
+	truncated = append(truncated, body[:limit]...)
+	return append(truncated, fmt.Sprintf("... (truncated, %d bytes total)", len(body))...)
+}
+
+// closeResponseBody closes the body of the given response, if any, printing any error if the debug is enabled.
+func (c *Client) closeResponseBody(ctx context.Context, resp *http.Response) {
+	if resp.Body == nil {
+		if c.isDebugEnabled(ctx) {
+			c.debugPrintln("warning: no response body to close")
+		}
+		return
+	}
+	if closeErr := resp.Body.Close(); closeErr != nil {
+		if c.isDebugEnabled(ctx) {
+			c.debugPrintln(fmt.Errorf("error while closing response body: %w", closeErr))
+		}
+	}
+}
+
+// preloadedBody is a response body read into memory, which might be read multiple times by seeking it back to its
+// start.
+type preloadedBody struct {
+	*bytes.Reader
+}
+
+// Close does nothing, since the body was already read into memory.
+func (b *preloadedBody) Close() error {
+	return nil
+}
+
+// preloadBody reads the body of the given response into memory, closing it and replacing it by a preloadedBody. It
+// returns the body read, even if partially.
+func (c *Client) preloadBody(ctx context.Context, resp *http.Response) ([]byte, error) {
+	b, err := io.ReadAll(resp.Body)
+	c.closeResponseBody(ctx, resp)
+	if err != nil {
+		return b, fmt.Errorf("error while preloading response body: %w", err)
+	}
+	resp.Body = &preloadedBody{Reader: bytes.NewReader(b)}
+	return b, nil
+}
+
+// handleResponse checks if the status code of the given response is considered successful, validates it, checks if
+// its body requires a new attempt, unless it is streamed, and then calls the given ReaderFunc. Any error returned will
+// allow a new attempt.
+func (c *Client) handleResponse(ctx context.Context, resp *http.Response, readerFunc ReaderFunc, stream bool) error {
+
+	// Checks if the status code is considered successful, if the success status codes were given.
+	if c.successStatusCodes != nil {
+		if _, ok := c.successStatusCodes[resp.StatusCode]; !ok {
+			return fmt.Errorf("unexpected status code: %s", resp.Status)
+		}
+	}
+
+	// Checks if the status code is a server error, if they should be retried regardless of the ReaderFunc.
+	if c.retryOn5xx && resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("server error status code: %s", resp.Status)
+	}
+
+	// Validates the response, if some validator was given.
+	if c.responseValidator != nil {
+		if err := c.responseValidator(resp); err != nil {
+			return err
+		}
+	}
+
+	// Buffers the response body to check it against the retry predicate, handing a fresh reader to the ReaderFunc.
+	if c.bodyRetryPredicate != nil && !stream {
+		body, err := io.ReadAll(resp.Body)
+		if closeErr := resp.Body.Close(); closeErr != nil && c.isDebugEnabled(ctx) {
+			c.debugPrintln(fmt.Errorf("error while closing response body: %w", closeErr))
+		}
+		if err != nil {
+			return fmt.Errorf("error while reading response body: %w", err)
+		}
+		if c.bodyRetryPredicate(body) {
+			return fmt.Errorf("response body matched the retry predicate")
+		}
+		resp.Body = &preloadedBody{Reader: bytes.NewReader(body)}
+	}
+
+	if c.readerTimeout > 0 && !stream {
+		return c.readWithTimeout(ctx, resp, readerFunc)
+	}
+	return readerFunc(resp)
+}
+
+// readWithTimeout calls the given ReaderFunc, returning an error if it doesn't return within the reader timeout, in
+// which case it is left running in the background.
+func (c *Client) readWithTimeout(ctx context.Context, resp *http.Response, readerFunc ReaderFunc) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- readerFunc(resp)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-c.clock.After(c.readerTimeout):
+		if c.isDebugEnabled(ctx) {
+			c.debugPrintln(fmt.Sprintf("warning: reader function exceeded the timeout of %v and was abandoned", c.readerTimeout))
+		}
+		return fmt.Errorf("reader function exceeded the timeout of %v", c.readerTimeout)
 	}
 }
@@ -0,0 +1,117 @@
+//go:build linux
+
+package hardy_test
+
+import (
+	"context"
+	"errors"
+	"github.com/diegohordi/hardy"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// listenWithFullBacklog listens on a loopback port whose accept queue is full, since no connection is ever accepted,
+// so the new connection attempts hang until the dialer gives up, as for a black-holed host.
+func listenWithFullBacklog(t *testing.T) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	// Shrinks the accept queue to a single connection.
+	rawConn, err := listener.(*net.TCPListener).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listenErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		listenErr = syscall.Listen(int(fd), 0)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if listenErr != nil {
+		t.Fatal(listenErr)
+	}
+
+	// Fills the accept queue until a connection attempt times out.
+	for i := 0; i < 8; i++ {
+		conn, err := net.DialTimeout("tcp", listener.Addr().String(), 100*time.Millisecond)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return listener
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+	}
+	t.Fatal("the accept queue of the listener couldn't be filled")
+	return nil
+}
+
+func TestClient_Try_WithConnectTimeout(t *testing.T) {
+	t.Parallel()
+
+	listener := listenWithFullBacklog(t)
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(3),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithConnectTimeout(50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+	req, _ := http.NewRequest(http.MethodGet, "http://"+listener.Addr().String(), nil)
+	result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+		return nil
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("TryWithResult() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+	if result.Attempts != 3 {
+		t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, 3)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("TryWithResult() took %v, want the dials to fail fast", elapsed)
+	}
+}
+
+func TestIsConnectivityError_WithConnectTimeout(t *testing.T) {
+	t.Parallel()
+
+	listener := listenWithFullBacklog(t)
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(2),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithConnectTimeout(50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://"+listener.Addr().String(), nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return nil
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+	if !hardy.IsConnectivityError(err) {
+		t.Errorf("IsConnectivityError() = false, want true for %v", err)
+	}
+	if !strings.Contains(err.Error(), "server unreachable after 2 attempts") {
+		t.Errorf("Try() error = %v, want it to tell the server was unreachable", err)
+	}
+}
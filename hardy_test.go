@@ -2,15 +2,31 @@ package hardy_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/diegohordi/hardy"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"testing/iotest"
 	"time"
 )
 
@@ -20,6 +36,8 @@ func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return f(req)
 }
 
+var errUnprocessable = errors.New("unprocessable entity")
+
 type BuggyReaderCloser struct {
 }
 
@@ -31,533 +49,774 @@ func (b BuggyReaderCloser) Close() error {
 	return fmt.Errorf("some close error")
 }
 
-func TestClient_Try(t *testing.T) {
-	t.Parallel()
-	type fields struct {
-		Client func() (*hardy.Client, error)
-	}
-	type args struct {
-		ctx          func() (context.Context, context.CancelFunc)
-		req          func() *http.Request
-		readerFunc   hardy.ReaderFunc
-		fallbackFunc hardy.FallbackFunc
+// RecorderDebugger is a Debugger that records each printed line.
+type RecorderDebugger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (r *RecorderDebugger) Println(v ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, fmt.Sprintln(v...))
+}
+
+func (r *RecorderDebugger) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.lines...)
+}
+
+// HMACSigner is a RequestSigner signing the method, path, headers and body of each request along with a fresh nonce.
+type HMACSigner struct {
+	key   []byte
+	nonce int32
+}
+
+func (s *HMACSigner) Sign(req *http.Request) error {
+	nonce := fmt.Sprint(atomic.AddInt32(&s.nonce, 1))
+	req.Header.Set("X-Nonce", nonce)
+	var body []byte
+	if req.GetBody != nil {
+		reader, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		if body, err = io.ReadAll(reader); err != nil {
+			return err
+		}
 	}
+	req.Header.Set("X-Signature", s.signature(req.Method, req.URL.Path, nonce, req.Header.Get("X-Api-Version"), body))
+	return nil
+}
+
+func (s *HMACSigner) signature(method, path, nonce, version string, body []byte) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(strings.Join([]string{method, path, nonce, version, string(body)}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// FakeClock is a Clock that never sleeps, recording each requested interval instead.
+type FakeClock struct {
+	mu        sync.Mutex
+	now       time.Time
+	intervals []time.Duration
+}
+
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.intervals = append(f.intervals, d)
+	f.now = f.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- f.now
+	return ch
+}
+
+func (f *FakeClock) Intervals() []time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Duration(nil), f.intervals...)
+}
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
 	tests := []struct {
-		name          string
-		fields        fields
-		args          args
-		wantClientErr bool
-		wantErr       bool
-		errWant       error
+		name    string
+		options []hardy.Option
+		wantErr bool
+		errWant error
 	}{
 		{
-			name: "should perform the request successfully",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusOK)
-							return resp.Result(), nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugDisabled(),
-					)
-				},
+			name: "should create the client with the default configuration",
+		},
+		{
+			name: "should create the client tuning the internal transport",
+			options: []hardy.Option{
+				hardy.WithMaxIdleConnsPerHost(10),
+				hardy.WithIdleConnTimeout(30 * time.Second),
+				hardy.WithForceHTTP2(false),
 			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					return nil
-				},
+		},
+		{
+			name: "should create the client using the proxy from environment",
+			options: []hardy.Option{
+				hardy.WithProxy("http://localhost:3128"),
+				hardy.WithProxyFromEnvironment(),
 			},
-			wantErr: false,
 		},
 		{
-			name: "should perform the request successfully with debugger enabled",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusOK)
-							return resp.Result(), nil
-						}),
-					}
-					var buf bytes.Buffer
-					logger := log.Default()
-					logger.SetOutput(&buf)
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugger(logger),
-						hardy.WithDebugDisabled(),
-					)
-				},
+			name: "should fail due to an invalid proxy URL",
+			options: []hardy.Option{
+				hardy.WithProxy("://invalid"),
 			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					return nil
-				},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a proxy URL without host",
+			options: []hardy.Option{
+				hardy.WithProxy("localhost"),
 			},
-			wantErr: false,
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should perform the request successfully closing the response body from the given ReaderFunc",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							_, _ = resp.WriteString("test body")
-							resp.WriteHeader(http.StatusOK)
-							return resp.Result(), nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugDisabled(),
-					)
-				},
+			name: "should fail due to a proxy given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithHttpClient(&http.Client{}),
+				hardy.WithProxy("http://localhost:3128"),
 			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					_, err := io.ReadAll(response.Body)
-					if err != nil {
-						return err
-					}
-					return response.Body.Close()
-				},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a User-Agent header with CRLF",
+			options: []hardy.Option{
+				hardy.WithUserAgentHeader("my-agent\r\nX-Injected: true"),
 			},
-			wantErr: false,
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should perform the request successfully with some error occur while closing the response body",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							_, _ = resp.WriteString("test body")
-							resp.WriteHeader(http.StatusOK)
-							return resp.Result(), nil
-						}),
-					}
-
-					var buf bytes.Buffer
-					logger := log.Default()
-					logger.SetOutput(&buf)
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugger(logger),
-					)
-				},
+			name: "should fail due to a client identity with CRLF",
+			options: []hardy.Option{
+				hardy.WithClientIdentity("myapp", "1.2.3\r\nX-Injected: true"),
 			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					buggyReader := BuggyReaderCloser{}
-					response.Body = buggyReader
-					return nil
-				},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a default header value with CRLF",
+			options: []hardy.Option{
+				hardy.WithDefaultHeaders(http.Header{"Accept": []string{"application/json\r\nX-Injected: true"}}),
 			},
-			wantErr: false,
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should perform the request successfully with no client User-Agent header",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusOK)
-							return resp.Result(), nil
-						}),
-					}
-					var buf bytes.Buffer
-					logger := log.Default()
-					logger.SetOutput(&buf)
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugger(logger),
-						hardy.WithNoUserAgentHeader(),
-					)
-				},
+			name: "should fail due to a default header name with CRLF",
+			options: []hardy.Option{
+				hardy.WithDefaultHeaders(http.Header{"Accept\r\nX-Injected": []string{"true"}}),
 			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					return nil
-				},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a default header name with a space",
+			options: []hardy.Option{
+				hardy.WithDefaultHeaders(http.Header{"X Api Version": []string{"2"}}),
 			},
-			wantErr: false,
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should perform the request successfully with custom User-Agent header",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusOK)
-							return resp.Result(), nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugDisabled(),
-						hardy.WithUserAgentHeader("my-own-user-agent-header"),
-					)
-				},
+			name: "should fail due to a default header name with a colon",
+			options: []hardy.Option{
+				hardy.WithDefaultHeaders(http.Header{"X-Api-Version:": []string{"2"}}),
 			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					return nil
-				},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a User-Agent suffix with CRLF",
+			options: []hardy.Option{
+				hardy.WithUserAgentSuffix("myapp/2.0\r\nX-Injected: true"),
 			},
-			wantErr: false,
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should perform the request successfully with a custom backoff multiplier",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusOK)
-							return resp.Result(), nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugDisabled(),
-						hardy.WithBackoffMultiplier(3),
-						hardy.WithMaxRetries(4),
-						hardy.WithWaitInterval(1*time.Millisecond),
-					)
-				},
-			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					return nil
-				},
+			name: "should fail due to no debug from context function given",
+			options: []hardy.Option{
+				hardy.WithDebugFromContext(nil),
 			},
-			wantErr: false,
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should perform the request successfully with a not acceptable backoff multiplier given",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusOK)
-							return resp.Result(), nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugDisabled(),
-						hardy.WithBackoffMultiplier(1),
-						hardy.WithMaxRetries(4),
-						hardy.WithWaitInterval(1*time.Millisecond),
-					)
-				},
-			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					return nil
-				},
+			name: "should fail due to no fallback hook given",
+			options: []hardy.Option{
+				hardy.WithOnFallback(nil),
 			},
-			wantErr: false,
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should try only one since the error got doesnt allow retries",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							return nil, fmt.Errorf("not retriable error")
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugDisabled(),
-						hardy.WithMaxRetries(4),
-						hardy.WithWaitInterval(1*time.Millisecond),
-					)
-				},
-			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					return nil
-				},
+			name: "should fail due to no retry policy given",
+			options: []hardy.Option{
+				hardy.WithRetryPolicy(nil),
 			},
 			wantErr: true,
-			errWant: hardy.ErrUnexpected,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should reach out four failure retries",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusServiceUnavailable)
-							return resp.Result(), nil
-						}),
-					}
-					var buf bytes.Buffer
-					logger := log.Default()
-					logger.SetOutput(&buf)
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugger(logger),
-						hardy.WithMaxRetries(4),
-						hardy.WithWaitInterval(1*time.Millisecond),
-					)
-				},
-			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					if response.StatusCode == http.StatusServiceUnavailable {
-						return fmt.Errorf("%s", response.Status)
-					}
-					return nil
-				},
+			name: "should fail due to an invalid exhaustion jitter",
+			options: []hardy.Option{
+				hardy.WithExhaustionJitter(0),
 			},
 			wantErr: true,
-			errWant: hardy.ErrMaxRetriesReached,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should reach out four failure retries and call the given fallback function",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusServiceUnavailable)
-							return resp.Result(), nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugDisabled(),
-						hardy.WithMaxRetries(4),
-						hardy.WithWaitInterval(1*time.Millisecond),
-					)
-				},
+			name: "should fail due to an invalid service unavailable backoff",
+			options: []hardy.Option{
+				hardy.WithServiceUnavailableBackoff(0),
 			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					if response.StatusCode == http.StatusServiceUnavailable {
-						return fmt.Errorf("%s", response.Status)
-					}
-					return nil
-				},
-				fallbackFunc: func() error {
-					return hardy.ErrUnexpected
-				},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to an invalid min interval",
+			options: []hardy.Option{
+				hardy.WithMinInterval(0),
 			},
 			wantErr: true,
-			errWant: hardy.ErrUnexpected,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should reach out four failure retries without max timeout",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusServiceUnavailable)
-							return resp.Result(), nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithMaxRetries(4),
-						hardy.WithDebugDisabled(),
-						hardy.WithWaitInterval(1*time.Millisecond),
-						hardy.WithMaxInterval(0),
-					)
-				},
+			name: "should fail due to a min interval greater than the max interval",
+			options: []hardy.Option{
+				hardy.WithMinInterval(2 * time.Second),
+				hardy.WithMaxInterval(time.Second),
 			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					if response.StatusCode == http.StatusServiceUnavailable {
-						return fmt.Errorf("%s", response.Status)
-					}
-					return nil
-				},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to manual body close given along with attempt timeout",
+			options: []hardy.Option{
+				hardy.WithManualBodyClose(),
+				hardy.WithAttemptTimeout(time.Second),
 			},
 			wantErr: true,
-			errWant: hardy.ErrMaxRetriesReached,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should reach out four failure retries respecting the max timeout defined",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusServiceUnavailable)
-							return resp.Result(), nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithMaxRetries(4),
-						hardy.WithDebugDisabled(),
-						hardy.WithWaitInterval(3*time.Millisecond),
-						hardy.WithMaxInterval(1*time.Millisecond),
-					)
-				},
+			name: "should fail due to an invalid max elapsed time",
+			options: []hardy.Option{
+				hardy.WithMaxElapsedTime(0),
 			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					if response.StatusCode == http.StatusServiceUnavailable {
-						return fmt.Errorf("%s", response.Status)
-					}
-					return nil
-				},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to an invalid attempt timeout",
+			options: []hardy.Option{
+				hardy.WithAttemptTimeout(-time.Second),
 			},
 			wantErr: true,
-			errWant: hardy.ErrMaxRetriesReached,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should fail due to the try context deadline",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							time.Sleep(10 * time.Second)
-							return &http.Response{}, nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithMaxRetries(4),
-						hardy.WithDebugDisabled(),
-						hardy.WithWaitInterval(10*time.Second),
-					)
-				},
+			name: "should fail due to no retry log fields function given",
+			options: []hardy.Option{
+				hardy.WithRetryLogFields(nil),
 			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.WithTimeout(context.TODO(), 3*time.Millisecond)
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					return nil
-				},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to no request rewriter given",
+			options: []hardy.Option{
+				hardy.WithRequestRewriter(nil),
 			},
 			wantErr: true,
-			errWant: context.DeadlineExceeded,
+			errWant: hardy.ErrInvalidClientConfiguration,
 		},
 		{
-			name: "should fail due to a nil http client given",
+			name: "should fail due to no default headers given",
+			options: []hardy.Option{
+				hardy.WithDefaultHeaders(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to an invalid max retries for status",
+			options: []hardy.Option{
+				hardy.WithMaxRetriesForStatus(map[int]int{http.StatusTooManyRequests: 0}),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to no success status codes given",
+			options: []hardy.Option{
+				hardy.WithSuccessStatusCodes(),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a nil TLS configuration",
+			options: []hardy.Option{
+				hardy.WithTLSConfig(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to insecure skip verify given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithHttpClient(&http.Client{}),
+				hardy.WithInsecureSkipVerify(),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to an invalid min TLS version",
+			options: []hardy.Option{
+				hardy.WithMinTLSVersion(tls.VersionSSL30),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to min TLS version given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithHttpClient(&http.Client{}),
+				hardy.WithMinTLSVersion(tls.VersionTLS12),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a negative max idle connections per host",
+			options: []hardy.Option{
+				hardy.WithMaxIdleConnsPerHost(-1),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to an invalid connect timeout",
+			options: []hardy.Option{
+				hardy.WithConnectTimeout(0),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to connect timeout given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithHttpClient(&http.Client{}),
+				hardy.WithConnectTimeout(time.Second),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to expect continue given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithHttpClient(&http.Client{}),
+				hardy.WithExpect100Continue(true),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to an empty idempotency key header name",
+			options: []hardy.Option{
+				hardy.WithAutoIdempotencyKey(""),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to an idempotency key header name with control characters",
+			options: []hardy.Option{
+				hardy.WithAutoIdempotencyKey("Idempotency-Key\r\nX-Injected: true"),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to disable keep alives given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithHttpClient(&http.Client{}),
+				hardy.WithDisableKeepAlives(true),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a non-positive reader timeout",
+			options: []hardy.Option{
+				hardy.WithReaderTimeout(0),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to proxy from environment given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithHttpClient(&http.Client{}),
+				hardy.WithProxyFromEnvironment(),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a non-positive backoff base",
+			options: []hardy.Option{
+				hardy.WithBackoffBase(0),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a nil warning header hook",
+			options: []hardy.Option{
+				hardy.WithOnWarningHeader(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a non-positive body spill threshold",
+			options: []hardy.Option{
+				hardy.WithBodySpillThreshold(0),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a nil should continue function",
+			options: []hardy.Option{
+				hardy.WithShouldContinue(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a nil request signer",
+			options: []hardy.Option{
+				hardy.WithRequestSigner(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a nil trace context extractor",
+			options: []hardy.Option{
+				hardy.WithTraceContextPropagation(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a nil jitter function",
+			options: []hardy.Option{
+				hardy.WithJitterFunc(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a non-positive max response header bytes",
+			options: []hardy.Option{
+				hardy.WithMaxResponseHeaderBytes(0),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to max response header bytes given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithHttpClient(&http.Client{}),
+				hardy.WithMaxResponseHeaderBytes(1024),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to no cookie jar",
+			options: []hardy.Option{
+				hardy.WithCookieJar(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to cookie jar given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithCookieJar(&cookiejar.Jar{}),
+				hardy.WithHttpClient(&http.Client{}),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to no retry if function",
+			options: []hardy.Option{
+				hardy.WithRetryIf(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to retry if given along with a retry policy",
+			options: []hardy.Option{
+				hardy.WithRetryPolicy(hardy.DefaultRetryPolicy),
+				hardy.WithRetryIf(func(resp *http.Response, respErr error, readerErr error, attempt int) bool {
+					return true
+				}),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a name with control characters",
+			options: []hardy.Option{
+				hardy.WithName("payments\nforged line"),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to no metrics collector",
+			options: []hardy.Option{
+				hardy.WithRetryMetricsByStatus(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to no headers from context function",
+			options: []hardy.Option{
+				hardy.WithHeadersFromContext(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to negative max redirects",
+			options: []hardy.Option{
+				hardy.WithMaxRedirects(-1),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to max redirects given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithMaxRedirects(3),
+				hardy.WithHttpClient(&http.Client{}),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to follow redirects given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithHttpClient(&http.Client{}),
+				hardy.WithFollowRedirects(false),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to negative request body gzip min size",
+			options: []hardy.Option{
+				hardy.WithRequestBodyGzip(-1),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to no error mapper",
+			options: []hardy.Option{
+				hardy.WithErrorMapper(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to no endpoints",
+			options: []hardy.Option{
+				hardy.WithEndpoints(nil),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to an endpoint without weight",
+			options: []hardy.Option{
+				hardy.WithEndpoints([]hardy.WeightedEndpoint{{BaseURL: "http://primary", Weight: 0}}),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to duplicated endpoints",
+			options: []hardy.Option{
+				hardy.WithEndpoints([]hardy.WeightedEndpoint{{BaseURL: "http://primary", Weight: 1}, {BaseURL: "http://primary", Weight: 2}}),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to transport options given along with a custom http client",
+			options: []hardy.Option{
+				hardy.WithForceHTTP2(true),
+				hardy.WithHttpClient(&http.Client{}),
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := hardy.NewClient(tt.options...)
+			if err != nil != tt.wantErr {
+				t.Errorf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("NewClient() error = %v, errWant %v", err, tt.errWant)
+			}
+		})
+	}
+}
+
+func TestClient_UserAgent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		options []hardy.Option
+		want    string
+	}{
+		{
+			name: "should build the default User-Agent",
+			want: fmt.Sprintf("go-hardy-http-client/%s (%s)", hardy.ClientVersion, runtime.Version()),
+		},
+		{
+			name:    "should build the User-Agent with the platform info",
+			options: []hardy.Option{hardy.WithUserAgentPlatformInfo()},
+			want:    fmt.Sprintf("go-hardy-http-client/%s (%s; %s/%s)", hardy.ClientVersion, runtime.Version(), runtime.GOOS, runtime.GOARCH),
+		},
+		{
+			name:    "should build the User-Agent with a custom identity and the platform info",
+			options: []hardy.Option{hardy.WithClientIdentity("myapp", "1.2.3"), hardy.WithUserAgentPlatformInfo()},
+			want:    fmt.Sprintf("myapp/1.2.3 (%s; %s/%s)", runtime.Version(), runtime.GOOS, runtime.GOARCH),
+		},
+		{
+			name:    "should append the suffix to the default User-Agent",
+			options: []hardy.Option{hardy.WithUserAgentSuffix(" myapp/2.0 ")},
+			want:    fmt.Sprintf("go-hardy-http-client/%s (%s) myapp/2.0", hardy.ClientVersion, runtime.Version()),
+		},
+		{
+			name:    "should not append the suffix to the custom User-Agent",
+			options: []hardy.Option{hardy.WithUserAgentSuffix("myapp/2.0"), hardy.WithUserAgentHeader("my-own-user-agent-header")},
+			want:    "my-own-user-agent-header",
+		},
+		{
+			name:    "should keep the custom User-Agent",
+			options: []hardy.Option{hardy.WithUserAgentHeader("my-own-user-agent-header")},
+			want:    "my-own-user-agent-header",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := hardy.NewClient(tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := client.UserAgent(); got != tt.want {
+				t.Errorf("UserAgent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMustNewClient(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		options   []hardy.Option
+		wantPanic bool
+	}{
+		{
+			name:    "should create the client",
+			options: []hardy.Option{hardy.WithDebugDisabled()},
+		},
+		{
+			name:      "should panic due to an invalid configuration",
+			options:   []hardy.Option{hardy.WithHttpClient(nil)},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				r := recover()
+				if r != nil != tt.wantPanic {
+					t.Errorf("MustNewClient() panic = %v, wantPanic %v", r, tt.wantPanic)
+				}
+				if err, ok := r.(error); tt.wantPanic && (!ok || !errors.Is(err, hardy.ErrInvalidClientConfiguration)) {
+					t.Errorf("MustNewClient() panic = %v, errWant %v", r, hardy.ErrInvalidClientConfiguration)
+				}
+			}()
+			if client := hardy.MustNewClient(tt.options...); client == nil {
+				t.Errorf("MustNewClient() = nil")
+			}
+		})
+	}
+}
+
+func TestClient_Try(t *testing.T) {
+	t.Parallel()
+	type fields struct {
+		Client func() (*hardy.Client, error)
+	}
+	type args struct {
+		ctx          func() (context.Context, context.CancelFunc)
+		req          func() *http.Request
+		readerFunc   hardy.ReaderFunc
+		fallbackFunc hardy.FallbackFunc
+	}
+	tests := []struct {
+		name          string
+		fields        fields
+		args          args
+		wantClientErr bool
+		wantErr       bool
+		errWant       error
+	}{
+		{
+			name: "should perform the request successfully",
 			fields: fields{
 				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
 					return hardy.NewClient(
-						hardy.WithHttpClient(nil),
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
 					)
 				},
 			},
 			args: args{
 				ctx: func() (context.Context, context.CancelFunc) {
-					return context.WithTimeout(context.TODO(), 3*time.Millisecond)
+					return context.TODO(), nil
 				},
 				req: func() *http.Request {
 					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
@@ -567,138 +826,5888 @@ func TestClient_Try(t *testing.T) {
 					return nil
 				},
 			},
-			wantClientErr: true,
-			errWant:       hardy.ErrInvalidClientConfiguration,
+			wantErr: false,
+		},
+		{
+			name: "should perform the request successfully with debugger enabled",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					var buf bytes.Buffer
+					logger := log.Default()
+					logger.SetOutput(&buf)
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugger(logger),
+						hardy.WithDebugDisabled(),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should perform the request successfully closing the response body from the given ReaderFunc",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							_, _ = resp.WriteString("test body")
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					_, err := io.ReadAll(response.Body)
+					if err != nil {
+						return err
+					}
+					return response.Body.Close()
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should perform the request successfully with some error occur while closing the response body",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							_, _ = resp.WriteString("test body")
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+
+					var buf bytes.Buffer
+					logger := log.Default()
+					logger.SetOutput(&buf)
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugger(logger),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					buggyReader := BuggyReaderCloser{}
+					response.Body = buggyReader
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should perform the request successfully with no client User-Agent header",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					var buf bytes.Buffer
+					logger := log.Default()
+					logger.SetOutput(&buf)
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugger(logger),
+						hardy.WithNoUserAgentHeader(),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should perform the request successfully with custom User-Agent header",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithUserAgentHeader("my-own-user-agent-header"),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should perform the request successfully with a custom client identity",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							if !strings.HasPrefix(req.Header.Get("User-Agent"), "myapp/1.2.3 (go") {
+								resp.WriteHeader(http.StatusBadRequest)
+								return resp.Result(), nil
+							}
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithClientIdentity("myapp", "1.2.3"),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					if response.StatusCode != http.StatusOK {
+						return hardy.Permanent(fmt.Errorf("unexpected User-Agent"))
+					}
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should perform the request successfully with a custom backoff multiplier",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithBackoffMultiplier(3),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should fail due to a not acceptable backoff multiplier given",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithBackoffMultiplier(0.5),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantClientErr: true,
+			errWant:       hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should try only one since the error got doesnt allow retries",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							return nil, fmt.Errorf("not retriable error")
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrUnexpected,
+		},
+		{
+			name: "should retry on connection errors by default",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					var calls int32
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							if atomic.AddInt32(&calls, 1) < 3 {
+								return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+							}
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should reach out four failure retries due to connection resets",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrMaxRetriesReached,
+		},
+		{
+			name: "should try only once on connection errors when transport retries are disabled",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithNoRetryOnTransportErrors(),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrUnexpected,
+		},
+		{
+			name: "should try only once since certificate errors are not transient",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							return nil, x509.UnknownAuthorityError{}
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrUnexpected,
+		},
+		{
+			name: "should try only once since the error got is not a connection error",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							return nil, fmt.Errorf("unsupported protocol scheme")
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithRetryOnConnectionErrors(),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrUnexpected,
+		},
+		{
+			name: "should perform the request successfully with a response validator",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.Header().Set("Content-Type", "application/json")
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithResponseValidator(func(response *http.Response) error {
+							if response.Header.Get("Content-Type") != "application/json" {
+								return fmt.Errorf("unexpected content type")
+							}
+							return nil
+						}),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should reach out four failure retries due to the response validator",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.Header().Set("Content-Type", "text/plain")
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+						hardy.WithResponseValidator(func(response *http.Response) error {
+							if response.Header.Get("Content-Type") != "application/json" {
+								return fmt.Errorf("unexpected content type")
+							}
+							return nil
+						}),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return fmt.Errorf("reader should not be called")
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrMaxRetriesReached,
+		},
+		{
+			name: "should try only once since the reader func returned a permanent error",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					var calls int32
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							if atomic.AddInt32(&calls, 1) > 1 {
+								resp.WriteHeader(http.StatusOK)
+								return resp.Result(), nil
+							}
+							resp.WriteHeader(http.StatusUnprocessableEntity)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					if response.StatusCode == http.StatusUnprocessableEntity {
+						return hardy.Permanent(errUnprocessable)
+					}
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: errUnprocessable,
+		},
+		{
+			name: "should retry based on the response body and read it in the reader func",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					var calls int32
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							if atomic.AddInt32(&calls, 1) < 3 {
+								_, _ = resp.WriteString(`{"status":"RETRY"}`)
+								return resp.Result(), nil
+							}
+							_, _ = resp.WriteString(`{"status":"OK"}`)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+						hardy.WithBodyRetryPredicate(func(body []byte) bool {
+							return bytes.Contains(body, []byte("RETRY"))
+						}),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					b, err := io.ReadAll(response.Body)
+					if err != nil {
+						return err
+					}
+					if string(b) != `{"status":"OK"}` {
+						return hardy.Permanent(fmt.Errorf("unexpected body: %s", b))
+					}
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should reach out four failure retries due to the response body",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							_, _ = resp.WriteString(`{"status":"RETRY"}`)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+						hardy.WithBodyRetryPredicate(func(body []byte) bool {
+							return bytes.Contains(body, []byte("RETRY"))
+						}),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrMaxRetriesReached,
+		},
+		{
+			name: "should retry buffering a request body without GetBody",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					var calls int32
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							b, _ := io.ReadAll(req.Body)
+							if string(b) != "payload" {
+								resp.WriteHeader(http.StatusBadRequest)
+								return resp.Result(), nil
+							}
+							if atomic.AddInt32(&calls, 1) < 3 {
+								resp.WriteHeader(http.StatusServiceUnavailable)
+								return resp.Result(), nil
+							}
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPut, "http://localhost:80", io.NopCloser(strings.NewReader("payload")))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					if response.StatusCode == http.StatusBadRequest {
+						return hardy.Permanent(fmt.Errorf("body not replayed"))
+					}
+					if response.StatusCode != http.StatusOK {
+						return fmt.Errorf("%s", response.Status)
+					}
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should try only once since the request body is not replayable",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					var calls int32
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							if atomic.AddInt32(&calls, 1) > 1 {
+								resp.WriteHeader(http.StatusOK)
+								return resp.Result(), nil
+							}
+							resp.WriteHeader(http.StatusServiceUnavailable)
+							return resp.Result(), nil
+						}),
+					}
+					var buf bytes.Buffer
+					logger := log.New(&buf, "", 0)
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugger(logger),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithBodyReplayPolicy(func(req *http.Request) bool {
+							return req.Method != http.MethodPut
+						}),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPut, "http://localhost:80", io.NopCloser(strings.NewReader("payload")))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					if response.StatusCode != http.StatusOK {
+						return fmt.Errorf("%s", response.Status)
+					}
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrBodyNotReplayable,
+		},
+		{
+			name: "should refresh the authorization header on each attempt",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							if req.Header.Get("Authorization") != "Bearer 2" {
+								resp.WriteHeader(http.StatusUnauthorized)
+								return resp.Result(), nil
+							}
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					var tokens int32
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+						hardy.WithBeforeRequest(func(ctx context.Context, req *http.Request) error {
+							req.Header.Set("Authorization", fmt.Sprintf("Bearer %d", atomic.AddInt32(&tokens, 1)))
+							return nil
+						}),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					if response.StatusCode != http.StatusOK {
+						return fmt.Errorf("%s", response.Status)
+					}
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should retry since the before request hook asked for a new attempt",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					var tokens int32
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+						hardy.WithBeforeRequest(func(ctx context.Context, req *http.Request) error {
+							if atomic.AddInt32(&tokens, 1) < 3 {
+								return fmt.Errorf("token service unavailable: %w", hardy.ErrRetryRequest)
+							}
+							return nil
+						}),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should reach out four failure retries since the before request hook kept asking for new attempts",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+						hardy.WithBeforeRequest(func(ctx context.Context, req *http.Request) error {
+							return hardy.ErrRetryRequest
+						}),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrMaxRetriesReached,
+		},
+		{
+			name: "should fail since the before request hook failed",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithBeforeRequest(func(ctx context.Context, req *http.Request) error {
+							return fmt.Errorf("no token available")
+						}),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrUnexpected,
+		},
+		{
+			name: "should call the reader func only for the success status codes",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					var calls int32
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							if atomic.AddInt32(&calls, 1) < 3 {
+								resp.WriteHeader(http.StatusBadGateway)
+								return resp.Result(), nil
+							}
+							resp.WriteHeader(http.StatusCreated)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+						hardy.WithSuccessStatusCodes(http.StatusOK, http.StatusCreated),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					if response.StatusCode != http.StatusCreated {
+						return hardy.Permanent(fmt.Errorf("unexpected status code: %s", response.Status))
+					}
+					return nil
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "should reach out four failure retries due to a status code out of the success ones",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusAccepted)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+						hardy.WithSuccessStatusCodes(http.StatusOK),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrMaxRetriesReached,
+		},
+		{
+			name: "should reach out four failure retries",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusServiceUnavailable)
+							return resp.Result(), nil
+						}),
+					}
+					var buf bytes.Buffer
+					logger := log.Default()
+					logger.SetOutput(&buf)
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugger(logger),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					if response.StatusCode == http.StatusServiceUnavailable {
+						return fmt.Errorf("%s", response.Status)
+					}
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrMaxRetriesReached,
+		},
+		{
+			name: "should reach out four failure retries and call the given fallback function",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusServiceUnavailable)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					if response.StatusCode == http.StatusServiceUnavailable {
+						return fmt.Errorf("%s", response.Status)
+					}
+					return nil
+				},
+				fallbackFunc: func() error {
+					return hardy.ErrUnexpected
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrUnexpected,
+		},
+		{
+			name: "should call the given fallback function immediately due to the response status code",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					var calls int32
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							if atomic.AddInt32(&calls, 1) > 1 {
+								resp.WriteHeader(http.StatusOK)
+								return resp.Result(), nil
+							}
+							resp.WriteHeader(http.StatusNotFound)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithImmediateFallbackOn(http.StatusNotFound, http.StatusGone),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return fmt.Errorf("reader should not be called")
+				},
+				fallbackFunc: func() error {
+					return errUnprocessable
+				},
+			},
+			wantErr: true,
+			errWant: errUnprocessable,
+		},
+		{
+			name: "should fail immediately due to the response status code without fallback function",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusNotFound)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithImmediateFallbackOn(http.StatusNotFound),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrImmediateFallback,
+		},
+		{
+			name: "should reach out four failure retries without max timeout",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusServiceUnavailable)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithMaxRetries(4),
+						hardy.WithDebugDisabled(),
+						hardy.WithWaitInterval(1*time.Millisecond),
+						hardy.WithMaxInterval(0),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					if response.StatusCode == http.StatusServiceUnavailable {
+						return fmt.Errorf("%s", response.Status)
+					}
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrMaxRetriesReached,
+		},
+		{
+			name: "should reach out four failure retries respecting the max timeout defined",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusServiceUnavailable)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithMaxRetries(4),
+						hardy.WithDebugDisabled(),
+						hardy.WithWaitInterval(3*time.Millisecond),
+						hardy.WithMaxInterval(1*time.Millisecond),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					if response.StatusCode == http.StatusServiceUnavailable {
+						return fmt.Errorf("%s", response.Status)
+					}
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrMaxRetriesReached,
+		},
+		{
+			name: "should stop retrying since the next interval exceeds the context deadline",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusServiceUnavailable)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithMaxRetries(4),
+						hardy.WithDebugDisabled(),
+						hardy.WithWaitInterval(10*time.Second),
+						hardy.WithMaxInterval(0),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.WithTimeout(context.TODO(), 1*time.Second)
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return fmt.Errorf("%s", response.Status)
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrMaxRetriesReached,
+		},
+		{
+			name: "should fail due to the try context deadline",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							time.Sleep(10 * time.Second)
+							return &http.Response{}, nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithMaxRetries(4),
+						hardy.WithDebugDisabled(),
+						hardy.WithWaitInterval(10*time.Second),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.WithTimeout(context.TODO(), 3*time.Millisecond)
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: context.DeadlineExceeded,
+		},
+		{
+			name: "should fail due to a nil http client given",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					return hardy.NewClient(
+						hardy.WithHttpClient(nil),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.WithTimeout(context.TODO(), 3*time.Millisecond)
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantClientErr: true,
+			errWant:       hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a zero-value client without HTTP client",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					return &hardy.Client{}, nil
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a nil debugger given",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					return hardy.NewClient(
+						hardy.WithDebugger(nil),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.WithTimeout(context.TODO(), 3*time.Millisecond)
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantClientErr: true,
+			errWant:       hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a nil clock given",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					return hardy.NewClient(
+						hardy.WithClock(nil),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantClientErr: true,
+			errWant:       hardy.ErrInvalidClientConfiguration,
+		},
+		{
+			name: "should fail due to a invalid request body while debugging the request",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+
+					var buf bytes.Buffer
+					logger := log.Default()
+					logger.SetOutput(&buf)
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugger(logger),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					buggyReader := BuggyReaderCloser{}
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", buggyReader)
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					buggyReader := BuggyReaderCloser{}
+					response.Body = buggyReader
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrUnexpected,
+		},
+		{
+			name: "should fail due to a invalid request body while cloning the request",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							resp := httptest.NewRecorder()
+							resp.WriteHeader(http.StatusOK)
+							return resp.Result(), nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.TODO(), nil
+				},
+				req: func() *http.Request {
+					buggyReader := BuggyReaderCloser{}
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", buggyReader)
+					req.GetBody = func() (io.ReadCloser, error) {
+						return req.Body, hardy.ErrUnexpected
+					}
+					return req
+				},
+				readerFunc: func(response *http.Response) error {
+					return nil
+				},
+			},
+			wantErr: true,
+			errWant: hardy.ErrUnexpected,
+		},
+		{
+			name: "should fail due to the empty reader func given",
+			fields: fields{
+				Client: func() (*hardy.Client, error) {
+					httpClient := &http.Client{
+						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+							time.Sleep(10 * time.Second)
+							return &http.Response{}, nil
+						}),
+					}
+					return hardy.NewClient(
+						hardy.WithHttpClient(httpClient),
+						hardy.WithDebugDisabled(),
+						hardy.WithMaxRetries(4),
+						hardy.WithWaitInterval(10*time.Second),
+					)
+				},
+			},
+			args: args{
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.WithTimeout(context.TODO(), 3*time.Millisecond)
+				},
+				req: func() *http.Request {
+					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+					return req
+				},
+				readerFunc: nil,
+			},
+			wantErr: true,
+			errWant: hardy.ErrNoReaderFuncFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Create the client
+			client, err := tt.fields.Client()
+			if err != nil != tt.wantClientErr {
+				t.Errorf("Client() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantClientErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("Client() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && errors.Is(err, tt.errWant) {
+				return
+			}
+
+			// Create the context
+			ctx, cancelFunc := tt.args.ctx()
+			if cancelFunc != nil {
+				defer cancelFunc()
+			}
+
+			// Call try
+			err = client.Try(ctx, tt.args.req(), tt.args.readerFunc, tt.args.fallbackFunc)
+			if err != nil != tt.wantErr {
+				t.Errorf("Try() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("Try() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithClock(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	clock := &FakeClock{now: time.Now()}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(4),
+		hardy.WithMaxInterval(0),
+		hardy.WithClock(clock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+
+	intervals := clock.Intervals()
+	if len(intervals) != 3 {
+		t.Fatalf("Try() waited %d times, want %d", len(intervals), 3)
+	}
+	for i := 1; i < len(intervals); i++ {
+		if intervals[i] <= intervals[i-1] {
+			t.Errorf("Try() interval %d = %v, want greater than %v", i, intervals[i], intervals[i-1])
+		}
+	}
+}
+
+func TestClient_Try_WithClock_ContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	clock := &FakeClock{now: time.Now().Add(time.Hour)}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(4),
+		hardy.WithMaxInterval(0),
+		hardy.WithClock(clock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	result, err := client.TryWithResult(ctx, req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("TryWithResult() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+	if result.Attempts != 4 {
+		t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, 4)
+	}
+}
+
+func TestClient_Try_WithBackoffMultiplier(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		multiplier float64
+		want       []time.Duration
+	}{
+		{
+			name:       "should wait a constant backoff",
+			multiplier: 1,
+			want:       []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name:       "should wait a gentle backoff",
+			multiplier: 1.5,
+			want:       []time.Duration{2250 * time.Millisecond, 3375 * time.Millisecond, 5062 * time.Millisecond},
+		},
+		{
+			name:       "should wait the default backoff",
+			multiplier: 2,
+			want:       []time.Duration{4 * time.Second, 8 * time.Second, 16 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusServiceUnavailable)
+					return resp.Result(), nil
+				}),
+			}
+			clock := &FakeClock{now: time.Now()}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(4),
+				hardy.WithWaitInterval(time.Second),
+				hardy.WithMaxInterval(0),
+				hardy.WithBackoffMultiplier(tt.multiplier),
+				hardy.WithClock(clock),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+			}
+
+			intervals := clock.Intervals()
+			if len(intervals) != len(tt.want) {
+				t.Fatalf("Try() waited %d times, want %d", len(intervals), len(tt.want))
+			}
+			for i := range intervals {
+				if intervals[i] < tt.want[i] || intervals[i] >= tt.want[i]+time.Second {
+					t.Errorf("Try() interval %d = %v, want %v plus a jitter lower than 1s", i, intervals[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithMinInterval(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		maxInterval time.Duration
+		wantMin     time.Duration
+		wantMax     time.Duration
+	}{
+		{
+			name:    "should not wait less than the min interval",
+			wantMin: 2 * time.Second,
+			wantMax: 3 * time.Second,
+		},
+		{
+			name:        "should clamp the min interval with the max interval",
+			maxInterval: 2 * time.Second,
+			wantMin:     2 * time.Second,
+			wantMax:     2 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusServiceUnavailable)
+					return resp.Result(), nil
+				}),
+			}
+			clock := &FakeClock{now: time.Now()}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(4),
+				hardy.WithWaitInterval(time.Millisecond),
+				hardy.WithMaxInterval(tt.maxInterval),
+				hardy.WithMinInterval(2*time.Second),
+				hardy.WithClock(clock),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+			}
+
+			intervals := clock.Intervals()
+			if len(intervals) != 3 {
+				t.Fatalf("Try() waited %d times, want %d", len(intervals), 3)
+			}
+			for i := range intervals {
+				if intervals[i] < tt.wantMin || intervals[i] > tt.wantMax {
+					t.Errorf("Try() interval %d = %v, want between %v and %v", i, intervals[i], tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithBackoffBase(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		options   []hardy.Option
+		wantFloor []time.Duration
+	}{
+		{
+			name:      "should grow the intervals off the wait interval",
+			wantFloor: []time.Duration{400 * time.Millisecond, 800 * time.Millisecond, 1600 * time.Millisecond},
+		},
+		{
+			name:      "should grow the intervals off the backoff base",
+			options:   []hardy.Option{hardy.WithBackoffBase(time.Second)},
+			wantFloor: []time.Duration{400 * time.Millisecond, 8 * time.Second, 16 * time.Second},
+		},
+		{
+			name:      "should grow the intervals as if not given when the backoff base is the wait interval",
+			options:   []hardy.Option{hardy.WithBackoffBase(100 * time.Millisecond)},
+			wantFloor: []time.Duration{400 * time.Millisecond, 800 * time.Millisecond, 1600 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusServiceUnavailable)
+					return resp.Result(), nil
+				}),
+			}
+			clock := &FakeClock{now: time.Now()}
+			options := append([]hardy.Option{
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(4),
+				hardy.WithWaitInterval(100 * time.Millisecond),
+				hardy.WithMaxInterval(30 * time.Second),
+				hardy.WithClock(clock),
+			}, tt.options...)
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+			}
+
+			intervals := clock.Intervals()
+			if len(intervals) != len(tt.wantFloor) {
+				t.Fatalf("Try() waited %d times, want %d", len(intervals), len(tt.wantFloor))
+			}
+			for i := range intervals {
+				if intervals[i] < tt.wantFloor[i] || intervals[i] >= tt.wantFloor[i]+time.Second {
+					t.Errorf("Try() interval %d = %v, want %v plus the jitter", i, intervals[i], tt.wantFloor[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithBackoffBase_SameAsWaitInterval(t *testing.T) {
+	t.Parallel()
+
+	// Gets the intervals waited without jitter, as per the given options.
+	intervalsOf := func(options ...hardy.Option) []time.Duration {
+		httpClient := &http.Client{
+			Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+				resp := httptest.NewRecorder()
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}),
+		}
+		clock := &FakeClock{now: time.Now()}
+		client, err := hardy.NewClient(append([]hardy.Option{
+			hardy.WithHttpClient(httpClient),
+			hardy.WithDebugDisabled(),
+			hardy.WithMaxRetries(5),
+			hardy.WithWaitInterval(500 * time.Millisecond),
+			hardy.WithMaxInterval(time.Minute),
+			hardy.WithJitterFunc(func(backoff time.Duration) time.Duration { return 0 }),
+			hardy.WithClock(clock),
+		}, options...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+		err = client.Try(context.TODO(), req, func(response *http.Response) error {
+			return fmt.Errorf("%s", response.Status)
+		}, nil)
+		if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+			t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+		}
+		return clock.Intervals()
+	}
+
+	unset := intervalsOf()
+	explicit := intervalsOf(hardy.WithBackoffBase(500 * time.Millisecond))
+	if !reflect.DeepEqual(unset, explicit) {
+		t.Errorf("Try() intervals with the wait interval as the backoff base = %v, want %v", explicit, unset)
+	}
+}
+
+func TestClient_Try_WithJitterFunc(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	clock := &FakeClock{now: time.Now()}
+	var bases []time.Duration
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(4),
+		hardy.WithWaitInterval(100*time.Millisecond),
+		hardy.WithMaxInterval(time.Second),
+		hardy.WithClock(clock),
+		hardy.WithJitterFunc(func(base time.Duration) time.Duration {
+			bases = append(bases, base)
+			return 7 * time.Millisecond
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+
+	// The last interval is clamped to the max interval even after the jitter.
+	wantBases := []time.Duration{400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	wantIntervals := []time.Duration{407 * time.Millisecond, 807 * time.Millisecond, time.Second}
+	if !reflect.DeepEqual(bases, wantBases) {
+		t.Errorf("JitterFunc() bases = %v, want %v", bases, wantBases)
+	}
+	if got := clock.Intervals(); !reflect.DeepEqual(got, wantIntervals) {
+		t.Errorf("Try() intervals = %v, want %v", got, wantIntervals)
+	}
+}
+
+func TestClient_TryWithResult_UnlimitedRetriesIntervals(t *testing.T) {
+	t.Parallel()
+
+	const failures = 150
+	var calls int32
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			if atomic.AddInt32(&calls, 1) <= failures {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	clock := &FakeClock{now: time.Now()}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithUnlimitedRetries(),
+		hardy.WithWaitInterval(time.Millisecond),
+		hardy.WithMaxInterval(10*time.Second),
+		hardy.WithClock(clock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	result, err := client.TryWithResult(ctx, req, func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s", response.Status)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("TryWithResult() error = %v", err)
+	}
+
+	// The backoff grows past the max interval within a few retries, so all the following ones are clamped to it,
+	// instead of overflowing into short intervals.
+	intervals := clock.Intervals()
+	if len(intervals) != failures {
+		t.Fatalf("TryWithResult() waited %d intervals, want %d", len(intervals), failures)
+	}
+	for i := 20; i < len(intervals); i++ {
+		if intervals[i] != 10*time.Second {
+			t.Errorf("TryWithResult() interval %d = %v, want %v", i, intervals[i], 10*time.Second)
+		}
+	}
+	if got := len(result.Intervals); got != 100 {
+		t.Errorf("TryWithResult() recorded %d intervals, want %d", got, 100)
+	}
+}
+
+func TestClient_TryWithResult_Intervals(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			if atomic.AddInt32(&calls, 1) == 3 {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}
+			resp.WriteHeader(http.StatusBadGateway)
+			return resp.Result(), nil
+		}),
+	}
+	clock := &FakeClock{now: time.Now()}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(5),
+		hardy.WithWaitInterval(125*time.Millisecond),
+		hardy.WithMaxInterval(10*time.Second),
+		hardy.WithServiceUnavailableBackoff(5*time.Second),
+		hardy.WithClock(clock),
+		hardy.WithJitterFunc(func(base time.Duration) time.Duration {
+			return 0
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("TryWithResult() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+
+	// The interval after the 503 is raised to the service unavailable backoff.
+	want := []time.Duration{500 * time.Millisecond, time.Second, 5 * time.Second, 4 * time.Second}
+	if !reflect.DeepEqual(result.Intervals, want) {
+		t.Errorf("TryWithResult() intervals = %v, want %v", result.Intervals, want)
+	}
+	if got := clock.Intervals(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TryWithResult() waited %v, want %v", got, want)
+	}
+}
+
+func TestClient_Try_WithServiceUnavailableBackoff(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		statusCode int
+		retryAfter string
+		wantMin    time.Duration
+		wantMax    time.Duration
+	}{
+		{
+			name:       "should wait the service unavailable backoff",
+			statusCode: http.StatusServiceUnavailable,
+			wantMin:    5 * time.Second,
+			wantMax:    5 * time.Second,
+		},
+		{
+			name:       "should wait the regular backoff when the Retry-After header is present",
+			statusCode: http.StatusServiceUnavailable,
+			retryAfter: "1",
+			wantMax:    time.Second,
+		},
+		{
+			name:       "should wait the regular backoff for other status codes",
+			statusCode: http.StatusBadGateway,
+			wantMax:    time.Second,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					resp := httptest.NewRecorder()
+					if tt.retryAfter != "" {
+						resp.Header().Set("Retry-After", tt.retryAfter)
+					}
+					resp.WriteHeader(tt.statusCode)
+					return resp.Result(), nil
+				}),
+			}
+			clock := &FakeClock{now: time.Now()}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(3),
+				hardy.WithWaitInterval(time.Millisecond),
+				hardy.WithMaxInterval(time.Second),
+				hardy.WithServiceUnavailableBackoff(5*time.Second),
+				hardy.WithClock(clock),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+			}
+
+			intervals := clock.Intervals()
+			if len(intervals) != 2 {
+				t.Fatalf("Try() waited %d times, want %d", len(intervals), 2)
+			}
+			for i := range intervals {
+				if intervals[i] < tt.wantMin || intervals[i] > tt.wantMax {
+					t.Errorf("Try() interval %d = %v, want between %v and %v", i, intervals[i], tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithExhaustionJitter(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	clock := &FakeClock{now: time.Now()}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(2),
+		hardy.WithWaitInterval(time.Millisecond),
+		hardy.WithMaxInterval(time.Second),
+		hardy.WithExhaustionJitter(time.Minute),
+		hardy.WithClock(clock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fallbackCalled bool
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, func() error {
+		fallbackCalled = true
+		return nil
+	})
+	if err != nil || !fallbackCalled {
+		t.Fatalf("Try() error = %v, fallback called %v", err, fallbackCalled)
+	}
+
+	intervals := clock.Intervals()
+	if len(intervals) != 2 {
+		t.Fatalf("Try() waited %d times, want %d", len(intervals), 2)
+	}
+	if intervals[1] < 0 || intervals[1] >= time.Minute {
+		t.Errorf("Try() exhaustion wait = %v, want lower than %v", intervals[1], time.Minute)
+	}
+}
+
+func TestClient_Try_WithExhaustionJitter_ContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(2),
+		hardy.WithWaitInterval(time.Millisecond),
+		hardy.WithMaxInterval(time.Millisecond),
+		hardy.WithExhaustionJitter(time.Hour),
+		hardy.WithDeterministic(1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var fallbackCalled bool
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(ctx, req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, func() error {
+		fallbackCalled = true
+		return nil
+	})
+	if err != nil || !fallbackCalled {
+		t.Fatalf("Try() error = %v, fallback called %v", err, fallbackCalled)
+	}
+}
+
+func TestClient_Try_WithDeterministic(t *testing.T) {
+	t.Parallel()
+
+	intervals := func(seed int64) []time.Duration {
+		httpClient := &http.Client{
+			Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+				resp := httptest.NewRecorder()
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}),
+		}
+		clock := &FakeClock{now: time.Now()}
+		client, err := hardy.NewClient(
+			hardy.WithHttpClient(httpClient),
+			hardy.WithDebugDisabled(),
+			hardy.WithMaxRetries(4),
+			hardy.WithMaxInterval(0),
+			hardy.WithClock(clock),
+			hardy.WithDeterministic(seed),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+		err = client.Try(context.TODO(), req, func(response *http.Response) error {
+			return fmt.Errorf("%s", response.Status)
+		}, nil)
+		if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+			t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+		}
+		return clock.Intervals()
+	}
+
+	first, second := intervals(42), intervals(42)
+	if len(first) != 3 || len(second) != 3 {
+		t.Fatalf("Try() waited %d and %d times, want %d", len(first), len(second), 3)
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("Try() interval %d = %v and %v, want the same", i, first[i], second[i])
+		}
+	}
+}
+
+func TestClient_Do(t *testing.T) {
+	t.Parallel()
+	retryBudget, err := hardy.NewRetryBudget(1, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name           string
+		method         string
+		header         http.Header
+		options        []hardy.Option
+		contextBudget  int
+		statuses       []int
+		transportErr   error
+		wantErr        bool
+		errWant        error
+		wantStatusCode int
+		wantCalls      int32
+	}{
+		{
+			name:           "should return the response after retrying server errors",
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			wantStatusCode: http.StatusOK,
+			wantCalls:      3,
+		},
+		{
+			name:           "should not retry client errors",
+			statuses:       []int{http.StatusBadRequest},
+			wantStatusCode: http.StatusBadRequest,
+			wantCalls:      1,
+		},
+		{
+			name:           "should return the last response when max retries were reached",
+			statuses:       []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable},
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantCalls:      3,
+		},
+		{
+			name:           "should return the last response when the retry budget was exhausted",
+			options:        []hardy.Option{hardy.WithRetryBudget(retryBudget)},
+			statuses:       []int{http.StatusInternalServerError, http.StatusBadGateway},
+			wantStatusCode: http.StatusBadGateway,
+			wantCalls:      2,
+		},
+		{
+			name:           "should return the last response when the context budget was exhausted",
+			contextBudget:  2,
+			statuses:       []int{http.StatusInternalServerError, http.StatusBadGateway},
+			wantStatusCode: http.StatusBadGateway,
+			wantCalls:      2,
+		},
+		{
+			name: "should return the last response when the max elapsed time was reached",
+			options: []hardy.Option{
+				hardy.WithMaxElapsedTime(time.Minute),
+				hardy.WithWaitInterval(time.Hour),
+				hardy.WithMaxInterval(time.Hour),
+			},
+			statuses:       []int{http.StatusInternalServerError},
+			wantStatusCode: http.StatusInternalServerError,
+			wantCalls:      1,
+		},
+		{
+			name: "should return the last response when the retries were stopped by the should continue function",
+			options: []hardy.Option{
+				hardy.WithShouldContinue(func(attempt int, lastErr error, lastResp *http.Response) bool {
+					return attempt < 2
+				}),
+			},
+			statuses:       []int{http.StatusInternalServerError, http.StatusBadGateway},
+			wantStatusCode: http.StatusBadGateway,
+			wantCalls:      2,
+		},
+		{
+			name:           "should not retry server errors of non-idempotent requests",
+			method:         http.MethodPost,
+			statuses:       []int{http.StatusInternalServerError},
+			wantStatusCode: http.StatusInternalServerError,
+			wantCalls:      1,
+		},
+		{
+			name:           "should retry server errors of non-idempotent requests with an idempotency key",
+			method:         http.MethodPost,
+			header:         http.Header{"Idempotency-Key": []string{"key"}},
+			statuses:       []int{http.StatusInternalServerError, http.StatusOK},
+			wantStatusCode: http.StatusOK,
+			wantCalls:      2,
+		},
+		{
+			name:           "should retry too many requests of non-idempotent requests",
+			method:         http.MethodPost,
+			statuses:       []int{http.StatusTooManyRequests, http.StatusOK},
+			wantStatusCode: http.StatusOK,
+			wantCalls:      2,
+		},
+		{
+			name:   "should retry as per the given retry policy",
+			method: http.MethodPost,
+			options: []hardy.Option{
+				hardy.WithRetryPolicy(func(req *http.Request, resp *http.Response) bool {
+					return resp.StatusCode == http.StatusConflict
+				}),
+			},
+			statuses:       []int{http.StatusConflict, http.StatusInternalServerError},
+			wantStatusCode: http.StatusInternalServerError,
+			wantCalls:      2,
+		},
+		{
+			name:         "should not retry connection resets of non-idempotent requests",
+			method:       http.MethodPost,
+			transportErr: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+			wantErr:      true,
+			errWant:      hardy.ErrUnexpected,
+			wantCalls:    1,
+		},
+		{
+			name:         "should retry connection resets of non-idempotent requests with an idempotency key",
+			method:       http.MethodPost,
+			header:       http.Header{"Idempotency-Key": []string{"key"}},
+			transportErr: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+			wantErr:      true,
+			errWant:      hardy.ErrMaxRetriesReached,
+			wantCalls:    3,
+		},
+		{
+			name:         "should retry connection resets of non-idempotent requests if explicitly enabled",
+			method:       http.MethodPost,
+			options:      []hardy.Option{hardy.WithRetryOnConnectionErrors()},
+			transportErr: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+			wantErr:      true,
+			errWant:      hardy.ErrMaxRetriesReached,
+			wantCalls:    3,
+		},
+		{
+			name:         "should retry refused connections of non-idempotent requests",
+			method:       http.MethodPost,
+			transportErr: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			wantErr:      true,
+			errWant:      hardy.ErrMaxRetriesReached,
+			wantCalls:    3,
+		},
+		{
+			name:         "should fail due to a not retriable transport error",
+			transportErr: fmt.Errorf("not retriable error"),
+			wantErr:      true,
+			errWant:      hardy.ErrUnexpected,
+			wantCalls:    1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					call := atomic.AddInt32(&calls, 1)
+					if tt.transportErr != nil {
+						return nil, tt.transportErr
+					}
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(tt.statuses[call-1])
+					_, _ = resp.WriteString(fmt.Sprintf("attempt %d", call))
+					return resp.Result(), nil
+				}),
+			}
+			options := append([]hardy.Option{
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(3),
+				hardy.WithWaitInterval(1 * time.Millisecond),
+				hardy.WithMaxInterval(1 * time.Millisecond),
+			}, tt.options...)
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			ctx := context.Background()
+			if tt.contextBudget > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = hardy.ContextWithBudget(ctx, tt.contextBudget, time.Time{})
+				defer cancel()
+			}
+			req, _ := http.NewRequestWithContext(ctx, method, "http://localhost:80", nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			resp, err := client.Do(req)
+			if err != nil != tt.wantErr {
+				t.Fatalf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("Do() calls = %d, want %d", got, tt.wantCalls)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, tt.errWant) {
+					t.Errorf("Do() error = %v, errWant %v", err, tt.errWant)
+				}
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("Do() status code = %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := fmt.Sprintf("attempt %d", tt.wantCalls); string(b) != want {
+				t.Errorf("Do() body = %q, want %q", b, want)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, maxInFlight int32
+	release := make(chan struct{})
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+					break
+				}
+			}
+			<-release
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxConcurrency(2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			if err := client.Try(context.TODO(), req, func(response *http.Response) error {
+				return nil
+			}, nil); err != nil {
+				t.Errorf("Try() error = %v", err)
+			}
+		}()
+	}
+
+	// While the slots are taken, a new call should wait until its context is gone.
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(ctx, req, func(response *http.Response) error {
+		return nil
+	}, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Try() error = %v, errWant %v", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+	wg.Wait()
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("Try() max in flight = %d, want at most %d", got, 2)
+	}
+}
+
+func TestClient_Try_WithDebugWriter(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	var buf bytes.Buffer
+	debugger := &RecorderDebugger{}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugger(debugger),
+		hardy.WithDebugWriter(&buf),
+		hardy.WithNoUserAgentHeader(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+
+	dumps := buf.String()
+	if !strings.Contains(dumps, "POST / HTTP/1.1") {
+		t.Errorf("Try() request dump not found in %q", dumps)
+	}
+	if !strings.Contains(dumps, "HTTP/1.1 200 OK") {
+		t.Errorf("Try() response dump not found in %q", dumps)
+	}
+	for _, line := range debugger.Lines() {
+		if strings.Contains(line, "HTTP/1.1") {
+			t.Errorf("Try() dump sent to the debugger: %q", line)
+		}
+	}
+}
+
+func TestClient_Try_WithRetryLogFields(t *testing.T) {
+	t.Parallel()
+
+	type tenantKey struct{}
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	debugger := &RecorderDebugger{}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugger(debugger),
+		hardy.WithDebugWriter(io.Discard),
+		hardy.WithMaxRetries(2),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithRetryLogFields(func(ctx context.Context) map[string]any {
+			return map[string]any{
+				"tenant": ctx.Value(tenantKey{}),
+				"shard":  3,
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.TODO(), tenantKey{}, "acme")
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(ctx, req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+
+	var found int
+	for _, line := range debugger.Lines() {
+		if strings.HasPrefix(line, "attempt ") {
+			found++
+			if !strings.HasSuffix(line, " shard=3 tenant=acme\n") {
+				t.Errorf("Try() attempt line = %q, want the fields appended", line)
+			}
+		}
+	}
+	// Each attempt prints its timing and its failure.
+	if found != 4 {
+		t.Errorf("Try() printed %d attempt lines, want %d", found, 4)
+	}
+}
+
+func TestClient_Try_WithName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		clientName string
+		wantPrefix string
+	}{
+		{
+			name:       "should prefix the debug lines with the client name",
+			clientName: "payments",
+			wantPrefix: "[payments] attempt ",
+		},
+		{
+			name:       "should not prefix the debug lines without a client name",
+			wantPrefix: "attempt ",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusServiceUnavailable)
+					return resp.Result(), nil
+				}),
+			}
+			debugger := &RecorderDebugger{}
+			collector := &NamedRecorderMetricsCollector{}
+			var mu sync.Mutex
+			var hookNames []string
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugger(debugger),
+				hardy.WithDebugWriter(io.Discard),
+				hardy.WithMaxRetries(2),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithName(tt.clientName),
+				hardy.WithRetryMetricsByStatus(collector),
+				hardy.WithBeforeRequest(func(ctx context.Context, req *http.Request) error {
+					mu.Lock()
+					defer mu.Unlock()
+					hookNames = append(hookNames, hardy.ClientNameFrom(ctx))
+					return nil
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := client.Name(); got != tt.clientName {
+				t.Errorf("Name() = %q, want %q", got, tt.clientName)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+			}
+
+			lines := debugger.Lines()
+			if len(lines) == 0 {
+				t.Fatal("Try() printed no lines")
+			}
+			for _, line := range lines {
+				if !strings.HasPrefix(line, tt.wantPrefix) {
+					t.Errorf("Try() line = %q, want the prefix %q", line, tt.wantPrefix)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if want := []string{tt.clientName, tt.clientName}; !reflect.DeepEqual(hookNames, want) {
+				t.Errorf("Try() hook names = %q, want %q", hookNames, want)
+			}
+			if want := map[string]int{tt.clientName: 1}; !reflect.DeepEqual(collector.Retries(), want) {
+				t.Errorf("Try() retries by name = %v, want %v", collector.Retries(), want)
+			}
+		})
+	}
+}
+
+// NamedRecorderMetricsCollector is a NamedMetricsCollector that counts the retries by client name.
+type NamedRecorderMetricsCollector struct {
+	RecorderMetricsCollector
+	named map[string]int
+}
+
+func (r *NamedRecorderMetricsCollector) IncRetryForStatusNamed(name string, code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.named == nil {
+		r.named = make(map[string]int)
+	}
+	r.named[name]++
+}
+
+func (r *NamedRecorderMetricsCollector) Retries() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.named
+}
+
+func TestClient_Try_DebugTiming(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return nil, syscall.ECONNREFUSED
+			}
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	debugger := &RecorderDebugger{}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugger(debugger),
+		hardy.WithDebugWriter(io.Discard),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+
+	wantPrefixes := []string{"attempt 1: request failed in ", "attempt 2: 200 OK in "}
+	for _, prefix := range wantPrefixes {
+		found := false
+		for _, line := range debugger.Lines() {
+			if strings.HasPrefix(line, prefix) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Try() printed no line starting with %q", prefix)
+		}
+	}
+}
+
+func TestClient_Try_WithDebugFromContext(t *testing.T) {
+	t.Parallel()
+
+	type debugKey struct{}
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	var buf bytes.Buffer
+	debugger := &RecorderDebugger{}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugger(debugger),
+		hardy.WithDebugWriter(&buf),
+		hardy.WithDebugDisabled(),
+		hardy.WithDebugFromContext(func(ctx context.Context) bool {
+			return ctx.Value(debugKey{}) == true
+		}),
+		hardy.WithHeadersFromContext(func(ctx context.Context) http.Header {
+			return http.Header{"Invalid Name": {"value"}}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, ctx := range map[string]context.Context{
+		"/flagged":     context.WithValue(context.TODO(), debugKey{}, true),
+		"/not-flagged": context.TODO(),
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:80"+path, nil)
+		err = client.Try(ctx, req, func(response *http.Response) error {
+			return nil
+		}, nil)
+		if err != nil {
+			t.Fatalf("Try() error = %v", err)
+		}
+	}
+
+	dumps := buf.String()
+	if !strings.Contains(dumps, "GET /flagged HTTP/1.1") {
+		t.Errorf("Try() flagged request dump not found in %q", dumps)
+	}
+	if strings.Contains(dumps, "/not-flagged") {
+		t.Errorf("Try() not flagged request dumped in %q", dumps)
+	}
+
+	// The warnings are printed as per the context as well, so only for the flagged request.
+	warnings := 0
+	for _, line := range debugger.Lines() {
+		if strings.Contains(line, "invalid header name") {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("Try() invalid header name warnings = %d, want 1 in %q", warnings, debugger.Lines())
+	}
+}
+
+func TestClient_Try_DumpsSentRequest(t *testing.T) {
+	t.Parallel()
+
+	var sent http.Header
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req.Header.Clone()
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	var buf bytes.Buffer
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugWriter(&buf),
+		hardy.WithUserAgentHeader("hardy-dump-test"),
+		hardy.WithBeforeRequest(func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("X-Attempt", "1")
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+
+	dumps := buf.String()
+	if want := "User-Agent: " + sent.Get("User-Agent"); sent.Get("User-Agent") == "" || !strings.Contains(dumps, want) {
+		t.Errorf("Try() sent User-Agent %q not found in %q", sent.Get("User-Agent"), dumps)
+	}
+	if !strings.Contains(dumps, "X-Attempt: 1") {
+		t.Errorf("Try() header set by the before request hook not found in %q", dumps)
+	}
+}
+
+func TestClient_Try_WithDefaultHeaders(t *testing.T) {
+	t.Parallel()
+
+	var sent http.Header
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req.Header.Clone()
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithDefaultHeaders(http.Header{
+			"accept":        []string{"application/json"},
+			"X-Api-Version": []string{"2"},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	req.Header.Set("X-Api-Version", "3")
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+
+	if got := sent.Get("Accept"); got != "application/json" {
+		t.Errorf("Try() Accept = %q, want %q", got, "application/json")
+	}
+	if got := sent.Values("X-Api-Version"); len(got) != 1 || got[0] != "3" {
+		t.Errorf("Try() X-Api-Version = %q, want %q", got, "3")
+	}
+	if got := req.Header.Get("Accept"); got != "" {
+		t.Errorf("Try() mutated the given request with Accept = %q", got)
+	}
+}
+
+func TestClient_Try_WithRequestRewriter(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			b, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(b))
+			resp := httptest.NewRecorder()
+			if req.URL.Query().Get("retry") != "true" || req.Header.Get("X-Problematic") != "" {
+				resp.WriteHeader(http.StatusBadRequest)
+				return resp.Result(), nil
+			}
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	var lastStatusCodes []int
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithRequestRewriter(func(req *http.Request, lastResp *http.Response, attempt int) *http.Request {
+			lastStatusCodes = append(lastStatusCodes, lastResp.StatusCode)
+			rewritten := req.Clone(req.Context())
+			rewritten.Header.Del("X-Problematic")
+			query := rewritten.URL.Query()
+			query.Set("retry", "true")
+			rewritten.URL.RawQuery = query.Encode()
+			return rewritten
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", strings.NewReader("payload"))
+	req.Header.Set("X-Problematic", "true")
+	result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s", response.Status)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("TryWithResult() error = %v", err)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, 2)
+	}
+	if len(lastStatusCodes) != 1 || lastStatusCodes[0] != http.StatusBadRequest {
+		t.Errorf("TryWithResult() rewriter got last status codes %v, want %v", lastStatusCodes, []int{http.StatusBadRequest})
+	}
+	for i := range bodies {
+		if bodies[i] != "payload" {
+			t.Errorf("TryWithResult() attempt %d body = %q, want %q", i+1, bodies[i], "payload")
+		}
+	}
+}
+
+func TestClient_TryCtx(t *testing.T) {
+	t.Parallel()
+
+	type ctxKey struct{}
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errFallback := errors.New("fallback")
+	ctx := context.WithValue(context.TODO(), ctxKey{}, "value")
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.TryCtx(ctx, req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, func(ctx context.Context) error {
+		if ctx.Value(ctxKey{}) != "value" {
+			return errors.New("unexpected context")
+		}
+		return errFallback
+	})
+	if !errors.Is(err, errFallback) {
+		t.Errorf("TryCtx() error = %v, errWant %v", err, errFallback)
+	}
+}
+
+func TestClient_TryFunc(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			if req.URL.Path == "/unavailable" {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readerFunc := func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return errors.New(response.Status)
+		}
+		return nil
+	}
+
+	// The closures are built before the shared variable is reassigned, so they must keep the given requests.
+	var funcs []func() error
+	var req *http.Request
+	for _, path := range []string{"/ok", "/unavailable"} {
+		req, _ = http.NewRequest(http.MethodGet, "http://localhost:80"+path, nil)
+		funcs = append(funcs, client.TryFunc(context.TODO(), req, readerFunc, nil))
+	}
+
+	errs := make([]error, len(funcs))
+	var wg sync.WaitGroup
+	for i := range funcs {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = funcs[i]()
+		}()
+	}
+	wg.Wait()
+	if errs[0] != nil {
+		t.Errorf("TryFunc() error = %v, want nil", errs[0])
+	}
+	if !errors.Is(errs[1], hardy.ErrMaxRetriesReached) {
+		t.Errorf("TryFunc() error = %v, errWant %v", errs[1], hardy.ErrMaxRetriesReached)
+	}
+}
+
+func TestClient_Try_WithOnFallback(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	var events []string
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(2),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithOnFallback(func(lastErr error) {
+			if !errors.Is(lastErr, hardy.ErrMaxRetriesReached) {
+				t.Errorf("OnFallback() lastErr = %v, errWant %v", lastErr, hardy.ErrMaxRetriesReached)
+			}
+			events = append(events, "hook")
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, func() error {
+		events = append(events, "fallback")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+	if want := []string{"hook", "fallback"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("Try() events = %v, want %v", events, want)
+	}
+}
+
+func TestClient_Try_WithProxy(t *testing.T) {
+	t.Parallel()
+
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host == "hardy.example" {
+			atomic.AddInt32(&proxied, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithProxy(proxy.URL),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://hardy.example/resource", nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&proxied); got != 1 {
+		t.Errorf("Try() proxied requests = %d, want %d", got, 1)
+	}
+}
+
+func TestClient_Try_WithDisableKeepAlives(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		disable   bool
+		wantConns int32
+		wantClose bool
+	}{
+		{
+			name:      "should open a new connection for each attempt",
+			disable:   true,
+			wantConns: hardy.DefaultMaxRetries,
+			wantClose: true,
+		},
+		{
+			name:      "should reuse the connection between attempts",
+			disable:   false,
+			wantConns: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var conns int32
+			var closes int32
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Close {
+					atomic.AddInt32(&closes, 1)
+				}
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					atomic.AddInt32(&conns, 1)
+				}
+			}
+			server.Start()
+			defer server.Close()
+
+			client, err := hardy.NewClient(
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithDisableKeepAlives(tt.disable),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+			}
+			if got := atomic.LoadInt32(&conns); got != tt.wantConns {
+				t.Errorf("Try() connections = %d, want %d", got, tt.wantConns)
+			}
+			if got := atomic.LoadInt32(&closes) == hardy.DefaultMaxRetries; got != tt.wantClose {
+				t.Errorf("Try() asked to close the connection = %v, want %v", got, tt.wantClose)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithCookieJar(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123"})
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "abc123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithCookieJar(jar),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		if response.StatusCode == http.StatusUnauthorized {
+			return hardy.Permanent(errors.New(response.Status))
+		}
+		if response.StatusCode != http.StatusOK {
+			return errors.New(response.Status)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Try() error = %v, the session cookie wasn't sent on the retry", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Try() calls = %d, want 2", got)
+	}
+}
+
+func TestClient_TryWithResult(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			if atomic.AddInt32(&calls, 1) == 1 {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}
+			resp.WriteHeader(http.StatusOK)
+			_, _ = resp.WriteString("0123456789")
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s", response.Status)
+		}
+		_, err := io.ReadFull(response.Body, make([]byte, 4))
+		return err
+	}, nil)
+	if err != nil {
+		t.Fatalf("TryWithResult() error = %v", err)
+	}
+	want := hardy.TryResult{Attempts: 2, StatusCode: http.StatusOK, BytesRead: 4, Intervals: []time.Duration{time.Millisecond}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("TryWithResult() result = %+v, want %+v", result, want)
+	}
+}
+
+func TestClient_Try_WithMaxRetriesForStatus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		statusCodes  []int
+		wantAttempts int
+	}{
+		{
+			name:         "should exhaust the status specific max retries",
+			statusCodes:  []int{http.StatusServiceUnavailable},
+			wantAttempts: 2,
+		},
+		{
+			name:         "should be bounded by the global max retries",
+			statusCodes:  []int{http.StatusTooManyRequests},
+			wantAttempts: 4,
+		},
+		{
+			name:         "should use the global max retries for unlisted status codes",
+			statusCodes:  []int{http.StatusBadGateway},
+			wantAttempts: 4,
+		},
+		{
+			name:         "should use the global max retries once the attempts fail without a response",
+			statusCodes:  []int{http.StatusServiceUnavailable, 0},
+			wantAttempts: 4,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// The last status code is kept once the given ones are over, while 0 means a refused connection.
+			var calls int32
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					i := int(atomic.AddInt32(&calls, 1)) - 1
+					if i >= len(tt.statusCodes) {
+						i = len(tt.statusCodes) - 1
+					}
+					if tt.statusCodes[i] == 0 {
+						return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+					}
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(tt.statusCodes[i])
+					return resp.Result(), nil
+				}),
+			}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(4),
+				hardy.WithMaxRetriesForStatus(map[int]int{
+					http.StatusServiceUnavailable: 2,
+					http.StatusTooManyRequests:    10,
+				}),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("TryWithResult() error = %v, want %v", err, hardy.ErrMaxRetriesReached)
+			}
+			if result.Attempts != tt.wantAttempts {
+				t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestClient_TryResponse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		statusCodes    []int
+		wantErr        bool
+		errWant        error
+		wantStatusCode int
+	}{
+		{
+			name:           "should return the last response when the attempts failed",
+			statusCodes:    []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+			wantErr:        true,
+			errWant:        hardy.ErrMaxRetriesReached,
+			wantStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "should return the response when the attempts succeeded",
+			statusCodes:    []int{http.StatusBadGateway, http.StatusOK},
+			wantStatusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					call := atomic.AddInt32(&calls, 1)
+					resp := httptest.NewRecorder()
+					resp.Header().Set("X-Attempt", fmt.Sprint(call))
+					resp.WriteHeader(tt.statusCodes[call-1])
+					_, _ = resp.WriteString("body")
+					return resp.Result(), nil
+				}),
+			}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(2),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			resp, err := client.TryResponse(context.TODO(), req, func(response *http.Response) error {
+				if response.StatusCode != http.StatusOK {
+					return fmt.Errorf("%s", response.Status)
+				}
+				return nil
+			}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TryResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("TryResponse() error = %v, errWant %v", err, tt.errWant)
+			}
+			if resp == nil {
+				t.Fatal("TryResponse() response = nil")
+			}
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("TryResponse() status code = %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			if got := resp.Header.Get("X-Attempt"); got != "2" {
+				t.Errorf("TryResponse() X-Attempt = %q, want %q", got, "2")
+			}
+			if b, err := io.ReadAll(resp.Body); err != nil || len(b) != 0 {
+				t.Errorf("TryResponse() body = %q, %v, want empty", b, err)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	tests := []struct {
+		name    string
+		options []hardy.Option
+		wantErr bool
+		errWant error
+	}{
+		{
+			name:    "should fail due to the unknown certificate authority",
+			wantErr: true,
+			errWant: hardy.ErrUnexpected,
+		},
+		{
+			name: "should perform the request trusting the custom certificate authority",
+			options: []hardy.Option{
+				hardy.WithTLSConfig(&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}),
+			},
+		},
+		{
+			name: "should perform the request skipping the certificate verification",
+			options: []hardy.Option{
+				hardy.WithInsecureSkipVerify(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]hardy.Option{hardy.WithDebugDisabled()}, tt.options...)
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return nil
+			}, nil)
+			if err != nil != tt.wantErr {
+				t.Errorf("Try() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("Try() error = %v, errWant %v", err, tt.errWant)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithTLSConfig_Shared(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	// The same option value is given to both clients, while only one of them skips the certificate verification.
+	shared := hardy.WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})
+	dev, err := hardy.NewClient(hardy.WithDebugDisabled(), shared, hardy.WithInsecureSkipVerify())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prod, err := hardy.NewClient(hardy.WithDebugDisabled(), shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		client  *hardy.Client
+		wantErr bool
+		errWant error
+	}{
+		{
+			name:   "should skip the certificate verification as asked",
+			client: dev,
+		},
+		{
+			name:    "should verify the certificate regardless of the other clients sharing the TLS configuration",
+			client:  prod,
+			wantErr: true,
+			errWant: hardy.ErrUnexpected,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			err := tt.client.Try(context.TODO(), req, func(response *http.Response) error {
+				return nil
+			}, nil)
+			if err != nil != tt.wantErr {
+				t.Errorf("Try() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("Try() error = %v, errWant %v", err, tt.errWant)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithMinTLSVersion(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	// The same TLS configuration is given to all the clients, while only some of them raise the minimum version.
+	shared := hardy.WithTLSConfig(&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS10})
+
+	tests := []struct {
+		name       string
+		minVersion uint16
+		wantErr    bool
+		errWant    error
+	}{
+		{
+			name:       "should perform the request with a TLS 1.1 server when the minimum allows",
+			minVersion: tls.VersionTLS10,
+		},
+		{
+			name:       "should fail due to the TLS 1.1 server handshake when the minimum is TLS 1.2",
+			minVersion: tls.VersionTLS12,
+			wantErr:    true,
+			errWant:    hardy.ErrUnexpected,
+		},
+		{
+			name: "should keep the minimum of the shared TLS configuration for the other clients",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			options := []hardy.Option{hardy.WithDebugDisabled(), shared}
+			if tt.minVersion != 0 {
+				options = append(options, hardy.WithMinTLSVersion(tt.minVersion))
+			}
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return nil
+			}, nil)
+			if err != nil != tt.wantErr {
+				t.Errorf("Try() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("Try() error = %v, errWant %v", err, tt.errWant)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithDebugBodyLimit(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			if b, _ := io.ReadAll(req.Body); string(b) != "request-0123456789" {
+				resp.WriteHeader(http.StatusBadRequest)
+				return resp.Result(), nil
+			}
+			resp.WriteHeader(http.StatusOK)
+			_, _ = resp.WriteString("response-0123456789")
+			return resp.Result(), nil
+		}),
+	}
+	var buf bytes.Buffer
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugger(&RecorderDebugger{}),
+		hardy.WithDebugWriter(&buf),
+		hardy.WithDebugBodyLimit(8),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body []byte
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", strings.NewReader("request-0123456789"))
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return hardy.Permanent(fmt.Errorf("%s", response.Status))
+		}
+		body, err = io.ReadAll(response.Body)
+		return err
+	}, nil)
+	if err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+	if string(body) != "response-0123456789" {
+		t.Errorf("Try() body = %q, want %q", body, "response-0123456789")
+	}
+
+	dumps := buf.String()
+	for _, want := range []string{"request-... (truncated, 18 bytes total)", "response... (truncated, 19 bytes total)"} {
+		if !strings.Contains(dumps, want) {
+			t.Errorf("Try() dump %q not found in %q", want, dumps)
+		}
+	}
+}
+
+func TestClient_Try_WithResponseHeaderTimeout(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithResponseHeaderTimeout(50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Try() calls = %d, want %d", got, 2)
+	}
+}
+
+func TestClient_Try_WithExpect100Continue(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		reject    bool
+		wantErr   bool
+		errWant   error
+		wantCalls int32
+	}{
+		{
+			name:      "should send the body again on each new attempt",
+			wantCalls: 2,
+		},
+		{
+			name:      "should not retry when the server rejects the expectation",
+			reject:    true,
+			wantErr:   true,
+			errWant:   hardy.ErrExpectationFailed,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				if r.Header.Get("Expect") != "100-continue" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if tt.reject {
+					w.WriteHeader(http.StatusExpectationFailed)
+					return
+				}
+				if b, _ := io.ReadAll(r.Body); string(b) != "large upload" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if n == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := hardy.NewClient(
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithExpect100Continue(true),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("large upload"))
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				if response.StatusCode != http.StatusOK {
+					return fmt.Errorf("%s", response.Status)
+				}
+				return nil
+			}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Try() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("Try() error = %v, errWant %v", err, tt.errWant)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("Try() calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithAttemptTimeout(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		options        []hardy.Option
+		stallAttempts  int32
+		wantErr        bool
+		errWant        error
+		wantCalls      int32
+		wantMaxElapsed time.Duration
+	}{
+		{
+			name: "should perform a new attempt after the attempt timeout",
+			options: []hardy.Option{
+				hardy.WithAttemptTimeout(50 * time.Millisecond),
+			},
+			stallAttempts:  1,
+			wantCalls:      2,
+			wantMaxElapsed: time.Second,
+		},
+		{
+			name: "should bound the attempts by the remaining elapsed time",
+			options: []hardy.Option{
+				hardy.WithAttemptTimeout(time.Minute),
+				hardy.WithMaxElapsedTime(150 * time.Millisecond),
+			},
+			stallAttempts:  100,
+			wantErr:        true,
+			errWant:        hardy.ErrMaxElapsedTimeReached,
+			wantMaxElapsed: time.Second,
+		},
+		{
+			name: "should split the elapsed time among the attempts",
+			options: []hardy.Option{
+				hardy.WithAttemptTimeout(50 * time.Millisecond),
+				hardy.WithMaxElapsedTime(time.Minute),
+			},
+			stallAttempts:  2,
+			wantCalls:      3,
+			wantMaxElapsed: time.Second,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					if atomic.AddInt32(&calls, 1) <= tt.stallAttempts {
+						<-req.Context().Done()
+						return nil, req.Context().Err()
+					}
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusOK)
+					return resp.Result(), nil
+				}),
+			}
+			options := append([]hardy.Option{
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(100),
+				hardy.WithWaitInterval(1 * time.Millisecond),
+				hardy.WithMaxInterval(1 * time.Millisecond),
+			}, tt.options...)
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			started := time.Now()
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return nil
+			}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Try() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("Try() error = %v, errWant %v", err, tt.errWant)
+			}
+			if !tt.wantErr {
+				if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+					t.Errorf("Try() calls = %d, want %d", got, tt.wantCalls)
+				}
+			}
+			if elapsed := time.Since(started); elapsed > tt.wantMaxElapsed {
+				t.Errorf("Try() took %v, want at most %v", elapsed, tt.wantMaxElapsed)
+			}
+		})
+	}
+}
+
+func TestClient_TryResponse_WithManualBodyClose(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("kept open"))
+	}))
+	defer server.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithManualBodyClose(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := client.TryResponse(context.TODO(), req, func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s", response.Status)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("TryResponse() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("TryResponse() body was closed: %v", err)
+	}
+	if string(b) != "kept open" {
+		t.Errorf("TryResponse() body = %q, want %q", b, "kept open")
+	}
+}
+
+func TestClient_Try_WithManualBodyClose(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithManualBodyClose(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return nil
+	}, nil)
+	if !errors.Is(err, hardy.ErrInvalidClientConfiguration) {
+		t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrInvalidClientConfiguration)
+	}
+	if !strings.Contains(err.Error(), "manual body close can only be used along with Do or TryResponse") {
+		t.Errorf("Try() error = %v, want it to tell the methods allowed", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 0 {
+		t.Errorf("Try() attempts = %d, want %d", got, 0)
+	}
+}
+
+func TestClient_Try_WithTrailers(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("streamed body"))
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	defer server.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var status string
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		if _, err := io.ReadAll(response.Body); err != nil {
+			return err
+		}
+		status = response.Trailer.Get("Grpc-Status")
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+	if status != "0" {
+		t.Errorf("Try() trailer = %q, want %q", status, "0")
+	}
+}
+
+func TestClient_Try_WithAutoIdempotencyKey(t *testing.T) {
+	t.Parallel()
+	hash := sha256.Sum256([]byte("POST\nhttp://localhost:80/orders\npayload"))
+	tests := []struct {
+		name   string
+		header string
+		body   string
+		want   string
+	}{
+		{
+			name: "should set the idempotency key computed from the request",
+			body: "payload",
+			want: hex.EncodeToString(hash[:]),
+		},
+		{
+			name:   "should keep the idempotency key given by the caller",
+			header: "some-key",
+			body:   "payload",
+			want:   "some-key",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var mu sync.Mutex
+			var keys []string
+			var bodies []string
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					b, _ := io.ReadAll(req.Body)
+					mu.Lock()
+					keys = append(keys, req.Header.Get("Idempotency-Key"))
+					bodies = append(bodies, string(b))
+					mu.Unlock()
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusServiceUnavailable)
+					return resp.Result(), nil
+				}),
+			}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithAutoIdempotencyKey("idempotency-key"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "http://localhost:80/orders", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set("Idempotency-Key", tt.header)
+			}
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(keys) != hardy.DefaultMaxRetries {
+				t.Fatalf("Try() attempts = %d, want %d", len(keys), hardy.DefaultMaxRetries)
+			}
+			for i := range keys {
+				if keys[i] != tt.want {
+					t.Errorf("Try() attempt %d Idempotency-Key = %q, want %q", i+1, keys[i], tt.want)
+				}
+				if bodies[i] != tt.body {
+					t.Errorf("Try() attempt %d body = %q, want %q", i+1, bodies[i], tt.body)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithReaderTimeout(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	debugger := &RecorderDebugger{}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugger(debugger),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithReaderTimeout(50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first reader hangs until the test is over.
+	release := make(chan struct{})
+	defer close(release)
+	var calls int32
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-release
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("TryWithResult() error = %v", err)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, 2)
+	}
+
+	warned := false
+	for _, line := range debugger.Lines() {
+		if strings.Contains(line, "reader function exceeded the timeout") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Errorf("TryWithResult() didn't warn about the abandoned reader function")
+	}
+}
+
+func TestClient_Try_WithReaderTimeout_WithClock(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	clock := &FakeClock{now: time.Now()}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithClock(clock),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithReaderTimeout(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The readers hang until the test is over, so each attempt times out as soon as the clock tells.
+	release := make(chan struct{})
+	defer close(release)
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		<-release
+		return nil
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+
+	timeouts := 0
+	for _, interval := range clock.Intervals() {
+		if interval == time.Hour {
+			timeouts++
+		}
+	}
+	if timeouts != hardy.DefaultMaxRetries {
+		t.Errorf("Try() reader timeouts waited through the clock = %d, want %d", timeouts, hardy.DefaultMaxRetries)
+	}
+}
+
+func TestClient_Try_WithRetryOn5xx(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		statusCodes  []int
+		readerErr    error
+		wantErr      error
+		wantAttempts int
+		wantReads    int32
+	}{
+		{
+			name:         "should retry the 5xx without calling the reader",
+			statusCodes:  []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK},
+			wantAttempts: 3,
+			wantReads:    1,
+		},
+		{
+			name:         "should stop on the 2xx",
+			statusCodes:  []int{http.StatusOK},
+			wantAttempts: 1,
+			wantReads:    1,
+		},
+		{
+			name:         "should retry the 2xx on a reader error",
+			statusCodes:  []int{http.StatusOK, http.StatusOK, http.StatusOK},
+			readerErr:    errUnprocessable,
+			wantErr:      hardy.ErrMaxRetriesReached,
+			wantAttempts: 3,
+			wantReads:    3,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(tt.statusCodes[atomic.AddInt32(&calls, 1)-1])
+					return resp.Result(), nil
+				}),
+			}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithRetryOn5xx(),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var reads int32
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+				atomic.AddInt32(&reads, 1)
+				return tt.readerErr
+			}, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("TryWithResult() error = %v, errWant %v", err, tt.wantErr)
+			}
+			if result.Attempts != tt.wantAttempts {
+				t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, tt.wantAttempts)
+			}
+			if got := atomic.LoadInt32(&reads); got != tt.wantReads {
+				t.Errorf("TryWithResult() reads = %d, want %d", got, tt.wantReads)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithOnWarningHeader(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			if atomic.AddInt32(&calls, 1) == 1 {
+				resp.Header().Add("Warning", `199 - "first warning"`)
+				resp.Header().Add("Warning", `199 - "second warning"`)
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}
+			resp.Header().Set("Deprecation", "true")
+			resp.Header().Set("Sunset", "Wed, 11 Nov 2026 23:59:59 GMT")
+			resp.Header().Set("X-Unrelated", "value")
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	var mu sync.Mutex
+	var got []string
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithOnWarningHeader(func(header, value string) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, header+": "+value)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s", response.Status)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("TryWithResult() error = %v", err)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, 2)
+	}
+
+	want := []string{
+		`Warning: 199 - "first warning"`,
+		`Warning: 199 - "second warning"`,
+		"Deprecation: true",
+		"Sunset: Wed, 11 Nov 2026 23:59:59 GMT",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TryWithResult() warning headers = %q, want %q", got, want)
+	}
+}
+
+func TestClient_Try_WithBodySpillThreshold(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		body      string
+		wantSpill bool
+	}{
+		{
+			name: "should buffer the body in memory",
+			body: "small",
+		},
+		{
+			name:      "should spill the body to a temporary file",
+			body:      strings.Repeat("large", 10),
+			wantSpill: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var mu sync.Mutex
+			var bodies []string
+			var files []string
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					if file, ok := req.Body.(*os.File); ok {
+						files = append(files, file.Name())
+					}
+					b, _ := io.ReadAll(req.Body)
+					_ = req.Body.Close()
+					bodies = append(bodies, string(b))
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusServiceUnavailable)
+					return resp.Result(), nil
+				}),
+			}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithBodySpillThreshold(16),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", io.NopCloser(strings.NewReader(tt.body)))
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(bodies) != hardy.DefaultMaxRetries {
+				t.Fatalf("Try() attempts = %d, want %d", len(bodies), hardy.DefaultMaxRetries)
+			}
+			for i := range bodies {
+				if bodies[i] != tt.body {
+					t.Errorf("Try() attempt %d body = %q, want %q", i+1, bodies[i], tt.body)
+				}
+			}
+			if spilled := len(files) > 0; spilled != tt.wantSpill {
+				t.Fatalf("Try() spilled the body = %v, want %v", spilled, tt.wantSpill)
+			}
+			for i := range files {
+				if _, err := os.Stat(files[i]); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("Try() left the body file %s behind: %v", files[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithBodySpillThreshold_Debug(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat("large", 10)
+	var mu sync.Mutex
+	var spilled int
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, ok := req.Body.(*os.File); ok {
+				spilled++
+			}
+			_, _ = io.Copy(io.Discard, req.Body)
+			_ = req.Body.Close()
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return resp.Result(), nil
+		}),
+	}
+	debugger := &RecorderDebugger{}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugger(debugger),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithBodySpillThreshold(16),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", io.NopCloser(strings.NewReader(body)))
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if spilled != hardy.DefaultMaxRetries {
+		t.Errorf("Try() attempts sent from the body file = %d, want %d", spilled, hardy.DefaultMaxRetries)
+	}
+	output := strings.Join(debugger.Lines(), "")
+	if strings.Contains(output, body) {
+		t.Errorf("Try() dumped the spilled body: %s", output)
+	}
+	if want := fmt.Sprintf("request body of %d bytes not dumped", len(body)); !strings.Contains(output, want) {
+		t.Errorf("Try() debug output = %s, want it to contain %q", output, want)
+	}
+}
+
+func TestClient_Try_WithUnlimitedRetries(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		options      []hardy.Option
+		cancelAfter  int32
+		wantErr      error
+		wantAttempts int32
+	}{
+		{
+			name:         "should retry until the max elapsed time is reached",
+			options:      []hardy.Option{hardy.WithMaxElapsedTime(100 * time.Millisecond)},
+			wantErr:      hardy.ErrMaxElapsedTimeReached,
+			wantAttempts: 10,
+		},
+		{
+			name:         "should retry until the context is canceled",
+			cancelAfter:  10,
+			wantErr:      context.Canceled,
+			wantAttempts: 10,
+		},
+		{
+			name:    "should fail due to no bound",
+			wantErr: hardy.ErrInvalidClientConfiguration,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.TODO()
+			cancel := context.CancelFunc(func() {})
+			if tt.cancelAfter > 0 {
+				ctx, cancel = context.WithCancel(ctx)
+			}
+			defer cancel()
+
+			var calls int32
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					if atomic.AddInt32(&calls, 1) == tt.cancelAfter {
+						cancel()
+					}
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusServiceUnavailable)
+					return resp.Result(), nil
+				}),
+			}
+			clock := &FakeClock{now: time.Now()}
+			options := append([]hardy.Option{
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithUnlimitedRetries(),
+				hardy.WithWaitInterval(10 * time.Millisecond),
+				hardy.WithMaxInterval(10 * time.Millisecond),
+				hardy.WithClock(clock),
+			}, tt.options...)
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(ctx, req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Try() error = %v, errWant %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&calls); got < tt.wantAttempts {
+				t.Errorf("Try() attempts = %d, want at least %d", got, tt.wantAttempts)
+			}
+			if tt.wantAttempts == 0 && atomic.LoadInt32(&calls) != 0 {
+				t.Errorf("Try() attempts = %d, want none", atomic.LoadInt32(&calls))
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithShouldContinue(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		stopAt       int
+		wantErr      error
+		wantAttempts int
+	}{
+		{
+			name:         "should stop when the function returns false",
+			stopAt:       2,
+			wantErr:      errUnprocessable,
+			wantAttempts: 2,
+		},
+		{
+			name:         "should continue while the function returns true",
+			wantErr:      hardy.ErrMaxRetriesReached,
+			wantAttempts: hardy.DefaultMaxRetries,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusServiceUnavailable)
+					return resp.Result(), nil
+				}),
+			}
+			var mu sync.Mutex
+			var attempts []int
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithShouldContinue(func(attempt int, lastErr error, lastResp *http.Response) bool {
+					mu.Lock()
+					defer mu.Unlock()
+					attempts = append(attempts, attempt)
+					if !errors.Is(lastErr, errUnprocessable) {
+						t.Errorf("ShouldContinue() lastErr = %v, want %v", lastErr, errUnprocessable)
+					}
+					if lastResp == nil || lastResp.StatusCode != http.StatusServiceUnavailable {
+						t.Errorf("ShouldContinue() lastResp = %v, want a %d response", lastResp, http.StatusServiceUnavailable)
+					}
+					return attempt != tt.stopAt
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+				return errUnprocessable
+			}, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("TryWithResult() error = %v, errWant %v", err, tt.wantErr)
+			}
+			if result.Attempts != tt.wantAttempts {
+				t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, tt.wantAttempts)
+			}
+
+			// The function is not called once the max retries were reached.
+			mu.Lock()
+			defer mu.Unlock()
+			for i := range attempts {
+				if attempts[i] != i+1 {
+					t.Errorf("ShouldContinue() attempt = %d, want %d", attempts[i], i+1)
+				}
+			}
+			if want := tt.wantAttempts; tt.stopAt == 0 && len(attempts) != want-1 {
+				t.Errorf("ShouldContinue() calls = %d, want %d", len(attempts), want-1)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithRequestSigner(t *testing.T) {
+	t.Parallel()
+
+	signer := &HMACSigner{key: []byte("secret")}
+	var mu sync.Mutex
+	var nonces []string
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			nonce := req.Header.Get("X-Nonce")
+			mu.Lock()
+			nonces = append(nonces, nonce)
+			attempt := len(nonces)
+			mu.Unlock()
+			resp := httptest.NewRecorder()
+			want := signer.signature(req.Method, req.URL.Path, nonce, req.Header.Get("X-Api-Version"), body)
+			if req.Header.Get("X-Signature") != want || string(body) != "payload" {
+				resp.WriteHeader(http.StatusUnauthorized)
+				return resp.Result(), nil
+			}
+			if attempt == 1 {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithDefaultHeaders(http.Header{"X-Api-Version": []string{"2"}}),
+		hardy.WithRequestSigner(signer),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:80/resource", strings.NewReader("payload"))
+	result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+		if response.StatusCode == http.StatusUnauthorized {
+			return hardy.Permanent(errors.New(response.Status))
+		}
+		if response.StatusCode != http.StatusOK {
+			return errors.New(response.Status)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("TryWithResult() error = %v", err)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, 2)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(nonces) != 2 || nonces[0] == nonces[1] {
+		t.Errorf("TryWithResult() nonces = %q, want a fresh one per attempt", nonces)
+	}
+}
+
+func TestClient_TryHead(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method != http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.URL.Path == "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case atomic.AddInt32(&calls, 1) == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Header().Set("Content-Length", "1234")
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name              string
+		url               string
+		wantStatusCode    int
+		wantContentLength string
+		errWant           error
+	}{
+		{
+			name:              "should get the headers retrying the 5xx",
+			url:               server.URL + "/resource",
+			wantStatusCode:    http.StatusOK,
+			wantContentLength: "1234",
+		},
+		{
+			name:           "should get the status code without retrying",
+			url:            server.URL + "/missing",
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "should get the last status code once max retries were reached",
+			url:            server.URL + "/unavailable",
+			wantStatusCode: http.StatusServiceUnavailable,
+			errWant:        hardy.ErrMaxRetriesReached,
+		},
+		{
+			name:    "should fail due to an invalid URL",
+			url:     "://invalid",
+			errWant: hardy.ErrInvalidClientConfiguration,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			header, statusCode, err := client.TryHead(context.TODO(), tt.url)
+			if (err != nil) != (tt.errWant != nil) || !errors.Is(err, tt.errWant) {
+				t.Fatalf("TryHead() error = %v, errWant %v", err, tt.errWant)
+			}
+			if statusCode != tt.wantStatusCode {
+				t.Errorf("TryHead() status code = %d, want %d", statusCode, tt.wantStatusCode)
+			}
+			if got := header.Get("Content-Length"); got != tt.wantContentLength {
+				t.Errorf("TryHead() Content-Length = %q, want %q", got, tt.wantContentLength)
+			}
+		})
+	}
+}
+
+func TestClient_TryPreflight(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.URL.Path == "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/forbidden":
+			w.Header().Set("Access-Control-Allow-Methods", "PUT")
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path == "/wildcard":
+			w.Header().Set("Access-Control-Allow-Methods", "*")
+			w.WriteHeader(http.StatusNoContent)
+		case atomic.AddInt32(&calls, 1) == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST,PUT")
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		method      string
+		wantAllowed bool
+		errWant     []error
+	}{
+		{
+			name:        "should allow a listed method retrying the 5xx",
+			url:         server.URL + "/resource",
+			method:      http.MethodPut,
+			wantAllowed: true,
+		},
+		{
+			name:   "should not allow a method not listed",
+			url:    server.URL + "/resource",
+			method: http.MethodDelete,
+		},
+		{
+			name:        "should allow any method through the wildcard",
+			url:         server.URL + "/wildcard",
+			method:      http.MethodPatch,
+			wantAllowed: true,
+		},
+		{
+			name:   "should not allow a method listed by a rejected preflight",
+			url:    server.URL + "/forbidden",
+			method: http.MethodPut,
+		},
+		{
+			name:    "should fail once max retries were reached",
+			url:     server.URL + "/unavailable",
+			method:  http.MethodPut,
+			errWant: []error{hardy.ErrPreflightFailed, hardy.ErrMaxRetriesReached},
+		},
+		{
+			name:    "should fail due to an invalid URL",
+			url:     "://invalid",
+			method:  http.MethodPut,
+			errWant: []error{hardy.ErrInvalidClientConfiguration},
+		},
+		{
+			name:    "should fail due to no method",
+			url:     server.URL + "/resource",
+			errWant: []error{hardy.ErrInvalidClientConfiguration},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := client.TryPreflight(context.TODO(), tt.url, tt.method)
+			if (err != nil) != (len(tt.errWant) > 0) {
+				t.Fatalf("TryPreflight() error = %v, errWant %v", err, tt.errWant)
+			}
+			for _, errWant := range tt.errWant {
+				if !errors.Is(err, errWant) {
+					t.Errorf("TryPreflight() error = %v, errWant %v", err, errWant)
+				}
+			}
+			if allowed != tt.wantAllowed {
+				t.Errorf("TryPreflight() allowed = %v, want %v", allowed, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestClient_Try_NilResponseBody(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Status: "200 OK"}, nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugger(&RecorderDebugger{}),
+		hardy.WithDebugWriter(io.Discard),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	var body []byte
+	result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s", response.Status)
+		}
+		var readErr error
+		body, readErr = io.ReadAll(response.Body)
+		return readErr
+	}, nil)
+	if err != nil {
+		t.Fatalf("TryWithResult() error = %v", err)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, 2)
+	}
+	if len(body) != 0 {
+		t.Errorf("TryWithResult() body = %q, want empty", body)
+	}
+}
+
+func TestClient_Try_WithTraceContextPropagation(t *testing.T) {
+	t.Parallel()
+
+	type traceKey struct{}
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tests := []struct {
+		name            string
+		header          string
+		wantTraceparent string
+		wantTracestate  string
+	}{
+		{
+			name:            "should propagate the trace context",
+			wantTraceparent: traceparent,
+			wantTracestate:  "vendor=value",
+		},
+		{
+			name:            "should keep the trace context set by the caller",
+			header:          "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			wantTraceparent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			wantTracestate:  "vendor=value",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var mu sync.Mutex
+			var sent []http.Header
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					mu.Lock()
+					sent = append(sent, req.Header.Clone())
+					mu.Unlock()
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusServiceUnavailable)
+					return resp.Result(), nil
+				}),
+			}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithTraceContextPropagation(func(ctx context.Context) (string, string) {
+					traceparent, _ := ctx.Value(traceKey{}).(string)
+					return traceparent, "vendor=value"
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.WithValue(context.TODO(), traceKey{}, traceparent)
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			if tt.header != "" {
+				req.Header.Set("traceparent", tt.header)
+			}
+			err = client.Try(ctx, req, func(response *http.Response) error {
+				return fmt.Errorf("%s", response.Status)
+			}, nil)
+			if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+				t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(sent) != hardy.DefaultMaxRetries {
+				t.Fatalf("Try() attempts = %d, want %d", len(sent), hardy.DefaultMaxRetries)
+			}
+			for i := range sent {
+				if got := sent[i].Get("traceparent"); got != tt.wantTraceparent {
+					t.Errorf("Try() attempt %d traceparent = %q, want %q", i+1, got, tt.wantTraceparent)
+				}
+				if got := sent[i].Get("tracestate"); got != tt.wantTracestate {
+					t.Errorf("Try() attempt %d tracestate = %q, want %q", i+1, got, tt.wantTracestate)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_TryAll(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+	calls := map[string]int{}
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			calls[req.URL.Path]++
+			attempt := calls[req.URL.Path]
+			mu.Unlock()
+			resp := httptest.NewRecorder()
+			switch {
+			case req.URL.Path == "/missing":
+				resp.WriteHeader(http.StatusNotFound)
+			case req.URL.Path == "/flaky" && attempt == 1:
+				resp.WriteHeader(http.StatusServiceUnavailable)
+			default:
+				resp.WriteHeader(http.StatusOK)
+			}
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithMaxConcurrency(2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{"/ok", "/flaky", "/missing", "", "/other"}
+	requests := make([]*http.Request, len(paths))
+	for i := range paths {
+		if paths[i] != "" {
+			requests[i], _ = http.NewRequest(http.MethodGet, "http://localhost:80"+paths[i], nil)
+		}
+	}
+	errs := client.TryAll(context.TODO(), requests, func(req *http.Request) hardy.ReaderFunc {
+		return func(response *http.Response) error {
+			switch {
+			case response.StatusCode == http.StatusNotFound:
+				return hardy.Permanent(errUnprocessable)
+			case response.StatusCode != http.StatusOK:
+				return fmt.Errorf("%s", response.Status)
+			}
+			return nil
+		}
+	})
+
+	wantErrs := []error{nil, nil, errUnprocessable, hardy.ErrUnexpected, nil}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("TryAll() errors = %d, want %d", len(errs), len(wantErrs))
+	}
+	for i := range wantErrs {
+		if (errs[i] != nil) != (wantErrs[i] != nil) || !errors.Is(errs[i], wantErrs[i]) {
+			t.Errorf("TryAll() error %d = %v, errWant %v", i, errs[i], wantErrs[i])
+		}
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("TryAll() requests in flight = %d, want at most %d", got, 2)
+	}
+}
+
+func TestClient_TryAll_Canceled(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	requests := make([]*http.Request, 3)
+	for i := range requests {
+		requests[i], _ = http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	}
+	errs := client.TryAll(ctx, requests, func(req *http.Request) hardy.ReaderFunc {
+		return func(response *http.Response) error {
+			return nil
+		}
+	})
+	for i := range errs {
+		if errs[i] == nil {
+			t.Errorf("TryAll() error %d = nil, want the requests stopped", i)
+		}
+	}
+}
+
+func TestClient_Try_WithMaxResponseHeaderBytes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		headerSize   int
+		wantErr      error
+		wantAttempts int32
+	}{
+		{
+			name:         "should accept the response headers within the limit",
+			headerSize:   100,
+			wantAttempts: 1,
+		},
+		{
+			name:         "should fail without retrying due to oversized response headers",
+			headerSize:   64 << 10,
+			wantErr:      hardy.ErrResponseHeadersTooLarge,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.Header().Set("X-Oversized", strings.Repeat("x", tt.headerSize))
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := hardy.NewClient(
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithMaxResponseHeaderBytes(4<<10),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				return nil
+			}, nil)
+			if (err != nil) != (tt.wantErr != nil) || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Try() error = %v, errWant %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantAttempts {
+				t.Errorf("Try() attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithRetryIf(t *testing.T) {
+	t.Parallel()
+
+	// Retries the 503 HTTP status code only on the first attempt, the 429 one always and no transport errors.
+	retryIf := func(resp *http.Response, respErr error, readerErr error, attempt int) bool {
+		if respErr != nil {
+			return false
+		}
+		switch resp.StatusCode {
+		case http.StatusServiceUnavailable:
+			return attempt < 2
+		case http.StatusTooManyRequests:
+			return true
+		default:
+			return false
+		}
+	}
+
+	tests := []struct {
+		name      string
+		responses []int
+		wantErr   error
+		wantCalls int32
+	}{
+		{
+			name:      "should retry the 503 HTTP status code only on the first attempt",
+			responses: []int{http.StatusServiceUnavailable},
+			wantErr:   errUnprocessable,
+			wantCalls: 2,
+		},
+		{
+			name:      "should always retry the 429 HTTP status code",
+			responses: []int{http.StatusTooManyRequests},
+			wantErr:   hardy.ErrMaxRetriesReached,
+			wantCalls: hardy.DefaultMaxRetries,
+		},
+		{
+			name:      "should retry until the request succeeds",
+			responses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			wantCalls: 3,
+		},
+		{
+			name:      "should not retry the 500 HTTP status code",
+			responses: []int{http.StatusInternalServerError},
+			wantErr:   errUnprocessable,
+			wantCalls: 1,
+		},
+		{
+			name:      "should not retry the transport errors",
+			wantErr:   syscall.ECONNREFUSED,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					call := int(atomic.AddInt32(&calls, 1))
+					if len(tt.responses) == 0 {
+						return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+					}
+					if call > len(tt.responses) {
+						call = len(tt.responses)
+					}
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(tt.responses[call-1])
+					return resp.Result(), nil
+				}),
+			}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithRetryIf(retryIf),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				if response.StatusCode != http.StatusOK {
+					return errUnprocessable
+				}
+				return nil
+			}, nil)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Try() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Try() error = %v, errWant %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("Try() calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// RecorderMetricsCollector is a MetricsCollector that counts the retries by HTTP status code.
+type RecorderMetricsCollector struct {
+	mu      sync.Mutex
+	retries map[int]int
+}
+
+func (r *RecorderMetricsCollector) IncRetryForStatus(code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.retries == nil {
+		r.retries = make(map[int]int)
+	}
+	r.retries[code]++
+}
+
+func (r *RecorderMetricsCollector) Retries() map[int]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.retries
+}
+
+func TestClient_Try_WithRetryMetricsByStatus(t *testing.T) {
+	t.Parallel()
+
+	// Fails with a 503, a 429, a transport error and then a 503 again, before the max retries are reached.
+	var calls int32
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			switch atomic.AddInt32(&calls, 1) {
+			case 2:
+				resp.WriteHeader(http.StatusTooManyRequests)
+			case 3:
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+			default:
+				resp.WriteHeader(http.StatusServiceUnavailable)
+			}
+			return resp.Result(), nil
+		}),
+	}
+	collector := &RecorderMetricsCollector{}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithMaxRetries(5),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithRetryMetricsByStatus(collector),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	err = client.Try(context.TODO(), req, func(response *http.Response) error {
+		return fmt.Errorf("%s", response.Status)
+	}, nil)
+	if !errors.Is(err, hardy.ErrMaxRetriesReached) {
+		t.Fatalf("Try() error = %v, errWant %v", err, hardy.ErrMaxRetriesReached)
+	}
+
+	// No retry is scheduled after the last attempt.
+	want := map[int]int{http.StatusServiceUnavailable: 2, http.StatusTooManyRequests: 1, 0: 1}
+	if got := collector.Retries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Try() retries = %v, want %v", got, want)
+	}
+}
+
+func TestClient_TryResponse_Concurrently(t *testing.T) {
+	t.Parallel()
+
+	// Each request tells the response it should get, so the outcomes of the concurrent calls might be checked.
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/refused" {
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+			}
+			if req.URL.Path == "/hanging" {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			}
+			resp := httptest.NewRecorder()
+			if req.URL.Path == "/unavailable" {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithMaxRetries(2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path       string
+		wantErr    error
+		wantStatus int
+	}{
+		{path: "/ok", wantStatus: http.StatusOK},
+		{path: "/unavailable", wantErr: hardy.ErrMaxRetriesReached, wantStatus: http.StatusServiceUnavailable},
+		{path: "/refused", wantErr: hardy.ErrMaxRetriesReached},
+		{path: "/hanging", wantErr: hardy.ErrTimeout},
+	}
+	readerFunc := func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return errors.New(response.Status)
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		tt := tests[i%len(tests)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+			if tt.path == "/hanging" {
+				ctx, cancel = context.WithTimeout(context.TODO(), 10*time.Millisecond)
+			}
+			defer cancel()
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80"+tt.path, nil)
+			resp, err := client.TryResponse(ctx, req, readerFunc, nil)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("TryResponse(%s) error = %v, want nil", tt.path, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("TryResponse(%s) error = %v, errWant %v", tt.path, err, tt.wantErr)
+			}
+			gotStatus := 0
+			if resp != nil {
+				gotStatus = resp.StatusCode
+			}
+			if gotStatus != tt.wantStatus {
+				t.Errorf("TryResponse(%s) status code = %d, want %d", tt.path, gotStatus, tt.wantStatus)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClient_Try_WithPreloadResponseBody(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		debug bool
+	}{
+		{
+			name: "should read the preloaded body multiple times",
+		},
+		{
+			name:  "should read the preloaded body multiple times regardless of the debug dumps",
+			debug: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The body of the first response breaks while being preloaded, allowing a new attempt.
+			var calls int32
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					if atomic.AddInt32(&calls, 1) == 1 {
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Header:     http.Header{},
+							Body:       io.NopCloser(io.MultiReader(strings.NewReader("he"), iotest.ErrReader(io.ErrUnexpectedEOF))),
+						}, nil
+					}
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(http.StatusOK)
+					_, _ = resp.WriteString("hello")
+					return resp.Result(), nil
+				}),
+			}
+			options := []hardy.Option{
+				hardy.WithHttpClient(httpClient),
+				hardy.WithWaitInterval(1 * time.Millisecond),
+				hardy.WithMaxInterval(1 * time.Millisecond),
+				hardy.WithPreloadResponseBody(),
+			}
+			if tt.debug {
+				options = append(options, hardy.WithDebugger(&RecorderDebugger{}), hardy.WithDebugWriter(io.Discard))
+			} else {
+				options = append(options, hardy.WithDebugDisabled())
+			}
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			result, err := client.TryWithResult(context.TODO(), req, func(response *http.Response) error {
+				for i := 0; i < 2; i++ {
+					b, err := io.ReadAll(response.Body)
+					if err != nil {
+						return err
+					}
+					if string(b) != "hello" {
+						return hardy.Permanent(fmt.Errorf("read #%d got %q, want %q", i+1, b, "hello"))
+					}
+					seeker, ok := response.Body.(io.Seeker)
+					if !ok {
+						return hardy.Permanent(errors.New("the body can't be seeked back"))
+					}
+					if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+						return hardy.Permanent(err)
+					}
+				}
+				return nil
+			}, nil)
+			if err != nil {
+				t.Fatalf("TryWithResult() error = %v, want nil", err)
+			}
+			if result.Attempts != 2 {
+				t.Errorf("TryWithResult() attempts = %d, want %d", result.Attempts, 2)
+			}
+			if result.BytesRead != int64(len("hello")) {
+				t.Errorf("TryWithResult() bytes read = %d, want %d", result.BytesRead, len("hello"))
+			}
+		})
+	}
+}
+
+func TestClient_Try_HopByHopHeaders(t *testing.T) {
+	t.Parallel()
+	hopByHop := []string{"Keep-Alive", "Te", "X-Connection-Scoped"}
+	endToEnd := []string{"Proxy-Authorization", "Upgrade", "X-End-To-End"}
+	tests := []struct {
+		name           string
+		options        []hardy.Option
+		connection     string
+		wantKept       bool
+		wantClose      bool
+		wantConnection string
+	}{
+		{
+			name:           "should strip the hop-by-hop headers from each attempt",
+			connection:     "X-Connection-Scoped, close",
+			wantClose:      true,
+			wantConnection: "close",
+		},
+		{
+			name:           "should keep asking for the upgrade while stripping the hop-by-hop headers",
+			connection:     "X-Connection-Scoped, Upgrade",
+			wantConnection: "Upgrade",
+		},
+		{
+			name:           "should keep the hop-by-hop headers if asked",
+			options:        []hardy.Option{hardy.WithKeepHopByHopHeaders()},
+			connection:     "X-Connection-Scoped, close",
+			wantKept:       true,
+			wantConnection: "X-Connection-Scoped, close",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Records the headers got on the wire by the last attempt, which is a retry.
+			var mu sync.Mutex
+			var got http.Header
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				got = r.Header.Clone()
+				mu.Unlock()
+				if atomic.AddInt32(&calls, 1) == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			options := append([]hardy.Option{
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1 * time.Millisecond),
+				hardy.WithMaxInterval(1 * time.Millisecond),
+			}, tt.options...)
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			req.Header.Set("Connection", tt.connection)
+			req.Header.Set("X-Connection-Scoped", "value")
+			req.Header.Set("Keep-Alive", "timeout=5")
+			req.Header.Set("Proxy-Authorization", "Basic dXNlcjpwYXNz")
+			req.Header.Set("Te", "trailers")
+			req.Header.Set("Upgrade", "websocket")
+			req.Header.Set("X-End-To-End", "value")
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				if response.StatusCode != http.StatusOK {
+					return errors.New(response.Status)
+				}
+				if response.Request.Close != tt.wantClose {
+					return hardy.Permanent(fmt.Errorf("request close = %v, want %v", response.Request.Close, tt.wantClose))
+				}
+				return nil
+			}, nil)
+			if err != nil {
+				t.Fatalf("Try() error = %v, want nil", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, header := range hopByHop {
+				if kept := got.Get(header) != ""; kept != tt.wantKept {
+					t.Errorf("Try() header %s kept = %v, want %v", header, kept, tt.wantKept)
+				}
+			}
+			for _, header := range endToEnd {
+				if got.Get(header) == "" {
+					t.Errorf("Try() header %s was stripped", header)
+				}
+			}
+			if connection := got.Get("Connection"); connection != tt.wantConnection {
+				t.Errorf("Try() header Connection = %q, want %q", connection, tt.wantConnection)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithHeadersFromContext(t *testing.T) {
+	t.Parallel()
+
+	type tenantKey struct{}
+	var mu sync.Mutex
+	var got []http.Header
+	httpClient := &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			got = append(got, req.Header.Clone())
+			attempts := len(got)
+			mu.Unlock()
+			resp := httptest.NewRecorder()
+			if attempts == 1 {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return resp.Result(), nil
+			}
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+	var calls int32
+	client, err := hardy.NewClient(
+		hardy.WithHttpClient(httpClient),
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+		hardy.WithDefaultHeaders(http.Header{"X-Api-Version": {"1"}, "Accept": {"application/json"}}),
+		hardy.WithHeadersFromContext(func(ctx context.Context) http.Header {
+			atomic.AddInt32(&calls, 1)
+			return http.Header{
+				"X-Tenant-Id":     {fmt.Sprint(ctx.Value(tenantKey{}))},
+				"accept-language": {"pt-BR"},
+				"X-Api-Version":   {"2"},
+				"X-Request-Id":    {"from-context"},
+				"X-Injected":      {"value\r\nX-Forged: true"},
+				"Bad Name":        {"value"},
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.TODO(), tenantKey{}, "acme")
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+	req.Header.Set("X-Request-Id", "from-caller")
+	err = client.Try(ctx, req, func(response *http.Response) error {
+		if response.StatusCode != http.StatusOK {
+			return errors.New(response.Status)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Try() error = %v, want nil", err)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Try() headers computed %d times, want once per attempt", got)
+	}
+	want := map[string]string{
+		"X-Tenant-Id":     "acme",
+		"Accept-Language": "pt-BR",
+		"X-Api-Version":   "2",
+		"X-Request-Id":    "from-caller",
+		"Accept":          "application/json",
+		"X-Injected":      "",
+		"X-Forged":        "",
+		"Bad Name":        "",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i, header := range got {
+		for key, value := range want {
+			if header.Get(key) != value {
+				t.Errorf("Try() attempt %d header %s = %q, want %q", i+1, key, header.Get(key), value)
+			}
+		}
+	}
+	if req.Header.Get("X-Tenant-Id") != "" {
+		t.Errorf("Try() mutated the given request headers: %v", req.Header)
+	}
+}
+
+func TestClient_Try_WithMaxRedirects(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		path      string
+		wantErr   error
+		wantCalls int32
+	}{
+		{
+			name:      "should follow the redirects within the max redirects",
+			path:      "/chain",
+			wantCalls: 3,
 		},
 		{
-			name: "should fail due to a nil debugger given",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					return hardy.NewClient(
-						hardy.WithDebugger(nil),
-					)
-				},
-			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.WithTimeout(context.TODO(), 3*time.Millisecond)
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					return nil
-				},
-			},
-			wantClientErr: true,
-			errWant:       hardy.ErrInvalidClientConfiguration,
+			name:      "should fail fast due to a redirect loop",
+			path:      "/loop",
+			wantErr:   hardy.ErrTooManyRedirects,
+			wantCalls: 4,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				switch r.URL.Path {
+				case "/loop":
+					http.Redirect(w, r, "/loop-back", http.StatusFound)
+				case "/loop-back":
+					http.Redirect(w, r, "/loop", http.StatusFound)
+				case "/chain":
+					http.Redirect(w, r, "/chain-next", http.StatusMovedPermanently)
+				case "/chain-next":
+					http.Redirect(w, r, "/done", http.StatusFound)
+				default:
+					w.WriteHeader(http.StatusOK)
+				}
+			}))
+			defer server.Close()
+
+			client, err := hardy.NewClient(
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithMaxRedirects(3),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				if response.StatusCode != http.StatusOK {
+					return errors.New(response.Status)
+				}
+				return nil
+			}, nil)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Try() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Try() error = %v, errWant %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("Try() calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithFollowRedirects(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		follow       bool
+		wantStatus   int
+		wantLocation string
+		wantCalls    int32
+	}{
+		{
+			name:         "should hand the redirect to the reader function without following it",
+			follow:       false,
+			wantStatus:   http.StatusFound,
+			wantLocation: "/target",
+			wantCalls:    1,
 		},
 		{
-			name: "should fail due to a invalid request body while debugging the request",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusOK)
-							return resp.Result(), nil
-						}),
-					}
+			name:       "should follow the redirect",
+			follow:     true,
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				if r.URL.Path == "/short" {
+					http.Redirect(w, r, "/target", http.StatusFound)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
 
-					var buf bytes.Buffer
-					logger := log.Default()
-					logger.SetOutput(&buf)
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugger(logger),
-					)
-				},
-			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					buggyReader := BuggyReaderCloser{}
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", buggyReader)
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					buggyReader := BuggyReaderCloser{}
-					response.Body = buggyReader
-					return nil
-				},
-			},
-			wantErr: true,
-			errWant: hardy.ErrUnexpected,
+			client, err := hardy.NewClient(
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithFollowRedirects(tt.follow),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var gotStatus int
+			var gotLocation string
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/short", nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				gotStatus = response.StatusCode
+				gotLocation = response.Header.Get("Location")
+				return nil
+			}, nil)
+			if err != nil {
+				t.Fatalf("Try() error = %v, want nil", err)
+			}
+			if gotStatus != tt.wantStatus {
+				t.Errorf("Try() status code = %d, want %d", gotStatus, tt.wantStatus)
+			}
+			if gotLocation != tt.wantLocation {
+				t.Errorf("Try() Location = %q, want %q", gotLocation, tt.wantLocation)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("Try() calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_Try_WithRequestBodyGzip(t *testing.T) {
+	t.Parallel()
+
+	largeBody := `{"items":"` + strings.Repeat("hardy", 200) + `"}`
+	tests := []struct {
+		name            string
+		body            string
+		header          http.Header
+		options         []hardy.Option
+		wantCompressed  bool
+		wantSpill       bool
+		wantContentType string
+	}{
+		{
+			name:           "should compress the body larger than the min size",
+			body:           largeBody,
+			header:         http.Header{"Content-Type": {"application/json"}},
+			wantCompressed: true,
 		},
 		{
-			name: "should fail due to a invalid request body while cloning the request",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							resp := httptest.NewRecorder()
-							resp.WriteHeader(http.StatusOK)
-							return resp.Result(), nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugDisabled(),
-					)
-				},
-			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.TODO(), nil
-				},
-				req: func() *http.Request {
-					buggyReader := BuggyReaderCloser{}
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", buggyReader)
-					req.GetBody = func() (io.ReadCloser, error) {
-						return req.Body, hardy.ErrUnexpected
-					}
-					return req
-				},
-				readerFunc: func(response *http.Response) error {
-					return nil
-				},
-			},
-			wantErr: true,
-			errWant: hardy.ErrUnexpected,
+			name:           "should compress the body to a temporary file if larger than the spill threshold",
+			body:           largeBody,
+			header:         http.Header{"Content-Type": {"application/json"}},
+			options:        []hardy.Option{hardy.WithBodySpillThreshold(16)},
+			wantCompressed: true,
+			wantSpill:      true,
 		},
 		{
-			name: "should fail due to the empty reader func given",
-			fields: fields{
-				Client: func() (*hardy.Client, error) {
-					httpClient := &http.Client{
-						Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
-							time.Sleep(10 * time.Second)
-							return &http.Response{}, nil
-						}),
-					}
-					return hardy.NewClient(
-						hardy.WithHttpClient(httpClient),
-						hardy.WithDebugDisabled(),
-						hardy.WithMaxRetries(4),
-						hardy.WithWaitInterval(10*time.Second),
-					)
-				},
-			},
-			args: args{
-				ctx: func() (context.Context, context.CancelFunc) {
-					return context.WithTimeout(context.TODO(), 3*time.Millisecond)
-				},
-				req: func() *http.Request {
-					req, _ := http.NewRequest(http.MethodPost, "http://localhost:80", bytes.NewReader(nil))
-					return req
-				},
-				readerFunc: nil,
-			},
-			wantErr: true,
-			errWant: hardy.ErrNoReaderFuncFound,
+			name:           "should compress the body in memory if smaller than the spill threshold",
+			body:           largeBody,
+			header:         http.Header{"Content-Type": {"application/json"}},
+			options:        []hardy.Option{hardy.WithBodySpillThreshold(1 << 20)},
+			wantCompressed: true,
+		},
+		{
+			name:   "should not compress the body smaller than the min size",
+			body:   `{"items":"hardy"}`,
+			header: http.Header{"Content-Type": {"application/json"}},
+		},
+		{
+			name:   "should not compress the body with a content encoding",
+			body:   largeBody,
+			header: http.Header{"Content-Type": {"application/json"}, "Content-Encoding": {"identity"}},
+		},
+		{
+			name:   "should not compress the body already compressed",
+			body:   largeBody,
+			header: http.Header{"Content-Type": {"application/zip"}},
 		},
 	}
 	for _, tt := range tests {
@@ -706,31 +6715,179 @@ func TestClient_Try(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			// Create the client
-			client, err := tt.fields.Client()
-			if err != nil != tt.wantClientErr {
-				t.Errorf("Client() error = %v, wantErr %v", err, tt.wantErr)
+			// Decodes the body got, failing the first attempt to check the body is replayed.
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reader := io.Reader(r.Body)
+				compressed := r.Header.Get("Content-Encoding") == "gzip"
+				if compressed {
+					gzipReader, err := gzip.NewReader(r.Body)
+					if err != nil {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					reader = gzipReader
+				}
+				body, err := io.ReadAll(reader)
+				if err != nil || string(body) != tt.body || compressed != tt.wantCompressed {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if atomic.AddInt32(&calls, 1) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			// Records the temporary files the bodies were read from, if any.
+			var mu sync.Mutex
+			var files []string
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					if file, ok := req.Body.(*os.File); ok {
+						mu.Lock()
+						files = append(files, file.Name())
+						mu.Unlock()
+					}
+					return http.DefaultTransport.RoundTrip(req)
+				}),
 			}
-			if tt.wantClientErr && !errors.Is(err, tt.errWant) {
-				t.Errorf("Client() error = %v, wantErr %v", err, tt.wantErr)
+			options := append([]hardy.Option{
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1 * time.Millisecond),
+				hardy.WithMaxInterval(1 * time.Millisecond),
+				hardy.WithRequestBodyGzip(256),
+			}, tt.options...)
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
 			}
-			if err != nil && errors.Is(err, tt.errWant) {
-				return
+
+			req, _ := http.NewRequest(http.MethodPost, server.URL, io.NopCloser(strings.NewReader(tt.body)))
+			req.Header = tt.header
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				if response.StatusCode == http.StatusBadRequest {
+					return hardy.Permanent(errors.New(response.Status))
+				}
+				if response.StatusCode != http.StatusOK {
+					return errors.New(response.Status)
+				}
+				return nil
+			}, nil)
+			if err != nil {
+				t.Fatalf("Try() error = %v, want nil", err)
+			}
+			if got := atomic.LoadInt32(&calls); got != 2 {
+				t.Errorf("Try() calls = %d, want %d", got, 2)
 			}
 
-			// Create the context
-			ctx, cancelFunc := tt.args.ctx()
-			if cancelFunc != nil {
-				defer cancelFunc()
+			mu.Lock()
+			defer mu.Unlock()
+			if spilled := len(files) > 0; spilled != tt.wantSpill {
+				t.Fatalf("Try() spilled the body = %v, want %v", spilled, tt.wantSpill)
+			}
+			for i := range files {
+				if _, err := os.Stat(files[i]); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("Try() left the body file %s behind: %v", files[i], err)
+				}
+			}
+		})
+	}
+}
+
+// AppError is an application error mapped from the errors built by the client.
+type AppError struct {
+	Code       hardy.ErrorCode
+	StatusCode int
+	Cause      error
+}
+
+func (e AppError) Error() string {
+	return fmt.Sprintf("app error %s (%d): %v", e.Code, e.StatusCode, e.Cause)
+}
+
+func (e AppError) Is(target error) bool {
+	return e.Code == target
+}
+
+func (e AppError) Unwrap() error {
+	return e.Cause
+}
+
+func TestClient_Try_WithErrorMapper(t *testing.T) {
+	t.Parallel()
+
+	errNotFound := errors.New("not found")
+	tests := []struct {
+		name           string
+		statusCode     int
+		wantErr        []error
+		wantMapped     bool
+		wantStatusCode int
+	}{
+		{
+			name:           "should map the error built by the client",
+			statusCode:     http.StatusServiceUnavailable,
+			wantErr:        []error{hardy.ErrMaxRetriesReached, errUnprocessable},
+			wantMapped:     true,
+			wantStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "should not map the permanent error returned by the reader function",
+			statusCode: http.StatusNotFound,
+			wantErr:    []error{errNotFound},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			httpClient := &http.Client{
+				Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					resp := httptest.NewRecorder()
+					resp.WriteHeader(tt.statusCode)
+					return resp.Result(), nil
+				}),
+			}
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(httpClient),
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(2),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithErrorMapper(func(code hardy.ErrorCode, cause error, resp *http.Response) error {
+					appErr := AppError{Code: code, Cause: cause}
+					if resp != nil {
+						appErr.StatusCode = resp.StatusCode
+					}
+					return appErr
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
 			}
 
-			// Call try
-			err = client.Try(ctx, tt.args.req(), tt.args.readerFunc, tt.args.fallbackFunc)
-			if err != nil != tt.wantErr {
-				t.Errorf("Try() error = %v, wantErr %v", err, tt.wantErr)
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				if response.StatusCode == http.StatusNotFound {
+					return hardy.Permanent(errNotFound)
+				}
+				return errUnprocessable
+			}, nil)
+			for _, errWant := range tt.wantErr {
+				if !errors.Is(err, errWant) {
+					t.Errorf("Try() error = %v, errWant %v", err, errWant)
+				}
 			}
-			if tt.wantErr && !errors.Is(err, tt.errWant) {
-				t.Errorf("Try() error = %v, wantErr %v", err, tt.wantErr)
+			var appErr AppError
+			if mapped := errors.As(err, &appErr); mapped != tt.wantMapped {
+				t.Fatalf("Try() error = %v, mapped %v, want %v", err, mapped, tt.wantMapped)
+			}
+			if appErr.StatusCode != tt.wantStatusCode {
+				t.Errorf("Try() mapped status code = %d, want %d", appErr.StatusCode, tt.wantStatusCode)
 			}
 		})
 	}
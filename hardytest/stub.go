@@ -0,0 +1,81 @@
+// Package hardytest provides utilities to test code relying on hardy, as stubs programmed to return a sequence of
+// HTTP status codes, so the retry-dependent behaviors might be easily exercised.
+package hardytest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// StubTransport is an http.RoundTripper returning the programmed HTTP status codes in sequence, repeating the last
+// one once the sequence is over, and recording the calls performed and the headers seen. It is safe for concurrent
+// use.
+type StubTransport struct {
+	mu          sync.Mutex
+	statusCodes []int
+	headers     []http.Header
+}
+
+// NewStubTransport creates a StubTransport returning the given HTTP status codes in sequence, as 503, 503 and 200.
+// If no status code is given, 200 is always returned.
+func NewStubTransport(statusCodes ...int) *StubTransport {
+	if len(statusCodes) == 0 {
+		statusCodes = []int{http.StatusOK}
+	}
+	return &StubTransport{
+		statusCodes: append([]int(nil), statusCodes...),
+	}
+}
+
+// RoundTrip records the given request and returns a response with the next programmed HTTP status code and an
+// empty body.
+func (s *StubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	call := len(s.headers)
+	s.headers = append(s.headers, req.Header.Clone())
+	statusCode := s.statusCodes[len(s.statusCodes)-1]
+	if call < len(s.statusCodes) {
+		statusCode = s.statusCodes[call]
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode: statusCode,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
+// Client returns an http.Client using the StubTransport, which might be given to hardy.WithHttpClient.
+func (s *StubTransport) Client() *http.Client {
+	return &http.Client{Transport: s}
+}
+
+// Calls returns the number of calls performed.
+func (s *StubTransport) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.headers)
+}
+
+// Headers returns the headers seen on each call performed, in order.
+func (s *StubTransport) Headers() []http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	headers := make([]http.Header, len(s.headers))
+	for i := range s.headers {
+		headers[i] = s.headers[i].Clone()
+	}
+	return headers
+}
@@ -0,0 +1,78 @@
+package hardytest_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/diegohordi/hardy"
+	"github.com/diegohordi/hardy/hardytest"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStubTransport(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		statusCodes []int
+		wantErr     bool
+		wantCalls   int
+	}{
+		{
+			name:        "should succeed after the programmed failures",
+			statusCodes: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			wantCalls:   3,
+		},
+		{
+			name:        "should repeat the last status code",
+			statusCodes: []int{http.StatusServiceUnavailable},
+			wantErr:     true,
+			wantCalls:   4,
+		},
+		{
+			name:      "should succeed by default",
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stub := hardytest.NewStubTransport(tt.statusCodes...)
+			client, err := hardy.NewClient(
+				hardy.WithHttpClient(stub.Client()),
+				hardy.WithDebugDisabled(),
+				hardy.WithMaxRetries(4),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+				hardy.WithUserAgentHeader("hardytest"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:80", nil)
+			err = client.Try(context.TODO(), req, func(response *http.Response) error {
+				if response.StatusCode != http.StatusOK {
+					return fmt.Errorf("%s", response.Status)
+				}
+				return nil
+			}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Try() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stub.Calls(); got != tt.wantCalls {
+				t.Errorf("Calls() = %d, want %d", got, tt.wantCalls)
+			}
+			headers := stub.Headers()
+			if len(headers) != tt.wantCalls {
+				t.Fatalf("Headers() = %d, want %d", len(headers), tt.wantCalls)
+			}
+			for i := range headers {
+				if got := headers[i].Get("User-Agent"); got != "hardytest" {
+					t.Errorf("Headers() call %d User-Agent = %q, want %q", i+1, got, "hardytest")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package hardy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+
+	// contentTypeHeader is the Content-Type header.
+	contentTypeHeader = "Content-Type"
+
+	// formContentType is the content type of URL encoded forms.
+	formContentType = "application/x-www-form-urlencoded"
+
+	// jsonContentType is the content type of JSON documents.
+	jsonContentType = "application/json"
+)
+
+// TryForm posts the given values as an URL encoded form to the given endpoint, using the given client to try
+// to perform the request as per its configurations. The request body is replayable, so it can be retried.
+// Besides the errors returned by Client.Try, it might return ErrInvalidClientConfiguration if the given
+// endpoint is not a valid URL.
+func TryForm(ctx context.Context, c *Client, endpoint string, values url.Values, readerFunc ReaderFunc, fallbackFunc FallbackFunc) error {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return newError(ErrInvalidClientConfiguration, withCause(err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return newError(ErrInvalidClientConfiguration, withCause(err))
+	}
+	req.Header.Set(contentTypeHeader, formContentType)
+	return c.Try(ctx, req, readerFunc, fallbackFunc)
+}
+
+// Post posts the given body encoded as JSON to the given endpoint, using the given client to try to perform the
+// request as per its configurations, and decodes the JSON response of a successful (2xx) attempt, which is returned.
+// The request body is replayable, so the attempts are retried while the RetryPolicy of the client allows, which by
+// default retries the 429 HTTP status code, as well as the 5xx ones if an Idempotency-Key header was added, while any
+// other status code, as well as a response that can't be decoded, fails without new attempts with ErrUnexpected. An
+// empty response body is decoded as the zero value. Besides the errors returned by Client.Try, it might return
+// ErrInvalidClientConfiguration if the given endpoint is not a valid URL or ErrUnexpected if the given body can't be
+// encoded.
+func Post[Req, Res any](ctx context.Context, c *Client, endpoint string, body Req) (Res, error) {
+	var res Res
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return res, newError(ErrInvalidClientConfiguration, withCause(err))
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return res, newError(ErrUnexpected, withCause(fmt.Errorf("error while encoding request body: %w", err)))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return res, newError(ErrInvalidClientConfiguration, withCause(err))
+	}
+	req.Header.Set(contentTypeHeader, jsonContentType)
+
+	// The RetryPolicy is taken from a snapshot of the configuration, as the one the attempts are performed with.
+	retryPolicy := c.snapshot().retryPolicy
+
+	// Guards the decoded response, which might be set by some abandoned ReaderFunc.
+	var mu sync.Mutex
+	readerFunc := func(response *http.Response) error {
+		if retryPolicy(req, response) {
+			return fmt.Errorf("retriable status code: %s", response.Status)
+		}
+		if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+			return Permanent(newError(ErrUnexpected, withCause(fmt.Errorf("unexpected status code: %s", response.Status))))
+		}
+		var decoded Res
+		if err := json.NewDecoder(response.Body).Decode(&decoded); err != nil && !errors.Is(err, io.EOF) {
+			return Permanent(newError(ErrUnexpected, withCause(fmt.Errorf("error while decoding response body: %w", err))))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		res = decoded
+		return nil
+	}
+	if err := c.Try(withMethodAwareRetries(ctx), req, readerFunc, nil); err != nil {
+		var zero Res
+		return zero, err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return res, nil
+}
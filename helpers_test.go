@@ -0,0 +1,216 @@
+package hardy_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/diegohordi/hardy"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTryForm(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("name") != "John Doe" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+		hardy.WithWaitInterval(1*time.Millisecond),
+		hardy.WithMaxInterval(1*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readerFunc := func(response *http.Response) error {
+		if response.StatusCode >= http.StatusInternalServerError {
+			return errors.New(response.Status)
+		}
+		if response.StatusCode != http.StatusOK {
+			return hardy.Permanent(errors.New(response.Status))
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		endpoint string
+		wantErr  bool
+		errWant  error
+	}{
+		{
+			name:     "should post the form retrying with a replayable body",
+			endpoint: server.URL,
+		},
+		{
+			name:     "should fail due to an invalid endpoint",
+			endpoint: "not a valid url",
+			wantErr:  true,
+			errWant:  hardy.ErrInvalidClientConfiguration,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := hardy.TryForm(context.TODO(), client, tt.endpoint, url.Values{"name": {"John Doe"}}, readerFunc, nil)
+			if err != nil != tt.wantErr {
+				t.Errorf("TryForm() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("TryForm() error = %v, errWant %v", err, tt.errWant)
+			}
+		})
+	}
+}
+
+func TestPost(t *testing.T) {
+	t.Parallel()
+
+	type message struct {
+		Text string `json:"text"`
+	}
+	type receipt struct {
+		ID   int    `json:"id"`
+		Text string `json:"text"`
+	}
+
+	var throttledCalls, flakyCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/throttled" && atomic.AddInt32(&throttledCalls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.URL.Path == "/flaky" && atomic.AddInt32(&flakyCalls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/unavailable" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/empty" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Path == "/malformed" {
+			_, _ = w.Write([]byte("{"))
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		var msg message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil || msg.Text == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(receipt{ID: 1, Text: msg.Text})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		options  []hardy.Option
+		endpoint string
+		body     message
+		want     receipt
+		wantErr  bool
+		errWant  error
+	}{
+		{
+			name:     "should post the message retrying with a replayable body",
+			endpoint: server.URL + "/throttled",
+			body:     message{Text: "hello"},
+			want:     receipt{ID: 1, Text: "hello"},
+		},
+		{
+			name: "should post the message retrying as per the given retry policy",
+			options: []hardy.Option{
+				hardy.WithRetryPolicy(func(req *http.Request, resp *http.Response) bool {
+					return resp.StatusCode >= http.StatusInternalServerError
+				}),
+			},
+			endpoint: server.URL + "/flaky",
+			body:     message{Text: "hello"},
+			want:     receipt{ID: 1, Text: "hello"},
+		},
+		{
+			name:     "should fail without retrying a server error of a non-idempotent request",
+			endpoint: server.URL + "/unavailable",
+			body:     message{Text: "hello"},
+			wantErr:  true,
+			errWant:  hardy.ErrUnexpected,
+		},
+		{
+			name:     "should decode an empty response as the zero value",
+			endpoint: server.URL + "/empty",
+			body:     message{Text: "hello"},
+		},
+		{
+			name:     "should fail without retrying due to a client error",
+			endpoint: server.URL,
+			body:     message{},
+			wantErr:  true,
+			errWant:  hardy.ErrUnexpected,
+		},
+		{
+			name:     "should fail due to a malformed response",
+			endpoint: server.URL + "/malformed",
+			body:     message{Text: "hello"},
+			wantErr:  true,
+			errWant:  hardy.ErrUnexpected,
+		},
+		{
+			name:     "should fail due to an invalid endpoint",
+			endpoint: "not a valid url",
+			wantErr:  true,
+			errWant:  hardy.ErrInvalidClientConfiguration,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]hardy.Option{
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1 * time.Millisecond),
+				hardy.WithMaxInterval(1 * time.Millisecond),
+			}, tt.options...)
+			client, err := hardy.NewClient(options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := hardy.Post[message, receipt](context.TODO(), client, tt.endpoint, tt.body)
+			if err != nil != tt.wantErr {
+				t.Errorf("Post() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("Post() error = %v, errWant %v", err, tt.errWant)
+			}
+			if got != tt.want {
+				t.Errorf("Post() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package hardy
+
+import (
+	"context"
+	"net/http"
+)
+
+// idempotencyKeyHeader is the header used to make non-idempotent requests safe to be retried.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// methodAwareRetriesKey is the context key telling that the call is made by a convenience method, as Do, whose
+// transport errors are retried as per the method of the request, as the DefaultRetryPolicy does for the responses.
+type methodAwareRetriesKey struct{}
+
+// withMethodAwareRetries returns a copy of the given context telling the transport errors should be retried as per
+// the method of the request.
+func withMethodAwareRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, methodAwareRetriesKey{}, true)
+}
+
+// methodAwareRetriesFrom checks if the transport errors of the call with the given context should be retried as per
+// the method of the request.
+func methodAwareRetriesFrom(ctx context.Context) bool {
+	methodAware, _ := ctx.Value(methodAwareRetriesKey{}).(bool)
+	return methodAware
+}
+
+// RetryPolicy defines the function that determines if a new attempt should be performed for the given request
+// based on the response got, used by the convenience methods, as Do and TryStream.
+type RetryPolicy func(req *http.Request, resp *http.Response) bool
+
+// DefaultRetryPolicy is the RetryPolicy used by default, which is method-aware, as follows:
+//
+//	| Status              | GET, HEAD, OPTIONS, TRACE, PUT, DELETE | Other methods, as POST and PATCH |
+//	|---------------------|----------------------------------------|----------------------------------|
+//	| 429                 | retry                                  | retry                            |
+//	| 5xx                 | retry                                  | retry with Idempotency-Key only  |
+//	| Any other           | no retry                               | no retry                         |
+//
+// A 429 means the request was not processed, so it is always safe to be retried, while a 5xx on a non-idempotent
+// request might have been partially processed, so it is only retried if an Idempotency-Key header was given.
+func DefaultRetryPolicy(req *http.Request, resp *http.Response) bool {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return isIdempotent(req)
+	default:
+		return false
+	}
+}
+
+// isIdempotent checks if the given request is idempotent, either by its method or by having an Idempotency-Key
+// header.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(idempotencyKeyHeader) != ""
+}
@@ -0,0 +1,61 @@
+package hardy_test
+
+import (
+	"github.com/diegohordi/hardy"
+	"net/http"
+	"testing"
+)
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		method         string
+		idempotencyKey string
+		statusCode     int
+		want           bool
+	}{
+		{
+			name:       "should retry server errors of idempotent requests",
+			method:     http.MethodPut,
+			statusCode: http.StatusBadGateway,
+			want:       true,
+		},
+		{
+			name:       "should not retry server errors of non-idempotent requests",
+			method:     http.MethodPatch,
+			statusCode: http.StatusBadGateway,
+		},
+		{
+			name:           "should retry server errors of non-idempotent requests with an idempotency key",
+			method:         http.MethodPost,
+			idempotencyKey: "key",
+			statusCode:     http.StatusBadGateway,
+			want:           true,
+		},
+		{
+			name:       "should retry too many requests of any request",
+			method:     http.MethodPost,
+			statusCode: http.StatusTooManyRequests,
+			want:       true,
+		},
+		{
+			name:       "should not retry client errors",
+			method:     http.MethodGet,
+			statusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, _ := http.NewRequest(tt.method, "http://localhost:80", nil)
+			if tt.idempotencyKey != "" {
+				req.Header.Set("Idempotency-Key", tt.idempotencyKey)
+			}
+			if got := hardy.DefaultRetryPolicy(req, &http.Response{StatusCode: tt.statusCode}); got != tt.want {
+				t.Errorf("DefaultRetryPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
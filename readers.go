@@ -0,0 +1,44 @@
+package hardy
+
+import (
+	"net/http"
+)
+
+// ChainReaders composes the given ReaderFunc into a single one, calling them in the given order and stopping at
+// the first error returned. Keep in mind that the response body is a stream, so once a ReaderFunc reads it, the
+// following ones will find it consumed.
+func ChainReaders(readerFuncs ...ReaderFunc) ReaderFunc {
+	return func(response *http.Response) error {
+		for i := range readerFuncs {
+			if readerFuncs[i] == nil {
+				continue
+			}
+			if err := readerFuncs[i](response); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// OnStatus returns a ReaderFunc that calls the given one only when the response has the given HTTP status code,
+// doing nothing otherwise.
+func OnStatus(statusCode int, readerFunc ReaderFunc) ReaderFunc {
+	return func(response *http.Response) error {
+		if response.StatusCode != statusCode {
+			return nil
+		}
+		return readerFunc(response)
+	}
+}
+
+// OnSuccess returns a ReaderFunc that calls the given function only when the response has a successful (2xx)
+// HTTP status code, doing nothing otherwise.
+func OnSuccess(fn func(response *http.Response) error) ReaderFunc {
+	return func(response *http.Response) error {
+		if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+			return nil
+		}
+		return fn(response)
+	}
+}
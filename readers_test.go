@@ -0,0 +1,156 @@
+package hardy_test
+
+import (
+	"errors"
+	"github.com/diegohordi/hardy"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestReaders(t *testing.T) {
+	t.Parallel()
+
+	errServer := errors.New("server error")
+
+	// newResponse creates a response with the given status code and body.
+	newResponse := func(statusCode int, body string) *http.Response {
+		resp := httptest.NewRecorder()
+		resp.WriteHeader(statusCode)
+		_, _ = resp.WriteString(body)
+		return resp.Result()
+	}
+
+	tests := []struct {
+		name       string
+		readerFunc func(calls *[]string) hardy.ReaderFunc
+		response   *http.Response
+		wantErr    error
+		wantCalls  []string
+	}{
+		{
+			name: "should call all the chained readers in order",
+			readerFunc: func(calls *[]string) hardy.ReaderFunc {
+				return hardy.ChainReaders(
+					func(response *http.Response) error {
+						*calls = append(*calls, "first")
+						return nil
+					},
+					nil,
+					func(response *http.Response) error {
+						*calls = append(*calls, "second")
+						return nil
+					},
+				)
+			},
+			response:  newResponse(http.StatusOK, ""),
+			wantCalls: []string{"first", "second"},
+		},
+		{
+			name: "should stop the chain at the first error",
+			readerFunc: func(calls *[]string) hardy.ReaderFunc {
+				return hardy.ChainReaders(
+					func(response *http.Response) error {
+						*calls = append(*calls, "first")
+						return errServer
+					},
+					func(response *http.Response) error {
+						*calls = append(*calls, "second")
+						return nil
+					},
+				)
+			},
+			response:  newResponse(http.StatusOK, ""),
+			wantErr:   errServer,
+			wantCalls: []string{"first"},
+		},
+		{
+			name: "should find the body consumed by a previous reader in the chain",
+			readerFunc: func(calls *[]string) hardy.ReaderFunc {
+				read := func(response *http.Response) error {
+					b, err := io.ReadAll(response.Body)
+					if err != nil {
+						return err
+					}
+					*calls = append(*calls, string(b))
+					return nil
+				}
+				return hardy.ChainReaders(read, read)
+			},
+			response:  newResponse(http.StatusOK, "body"),
+			wantCalls: []string{"body", ""},
+		},
+		{
+			name: "should call the reader only for the matching status",
+			readerFunc: func(calls *[]string) hardy.ReaderFunc {
+				return hardy.ChainReaders(
+					hardy.OnStatus(http.StatusServiceUnavailable, func(response *http.Response) error {
+						*calls = append(*calls, "unavailable")
+						return errServer
+					}),
+					hardy.OnStatus(http.StatusNotFound, func(response *http.Response) error {
+						*calls = append(*calls, "not found")
+						return nil
+					}),
+				)
+			},
+			response:  newResponse(http.StatusNotFound, ""),
+			wantCalls: []string{"not found"},
+		},
+		{
+			name: "should short-circuit on the matching status error",
+			readerFunc: func(calls *[]string) hardy.ReaderFunc {
+				return hardy.ChainReaders(
+					hardy.OnStatus(http.StatusServiceUnavailable, func(response *http.Response) error {
+						*calls = append(*calls, "unavailable")
+						return errServer
+					}),
+					hardy.OnSuccess(func(response *http.Response) error {
+						*calls = append(*calls, "success")
+						return nil
+					}),
+				)
+			},
+			response:  newResponse(http.StatusServiceUnavailable, ""),
+			wantErr:   errServer,
+			wantCalls: []string{"unavailable"},
+		},
+		{
+			name: "should call the success reader for 2xx status",
+			readerFunc: func(calls *[]string) hardy.ReaderFunc {
+				return hardy.OnSuccess(func(response *http.Response) error {
+					*calls = append(*calls, "success")
+					return nil
+				})
+			},
+			response:  newResponse(http.StatusNoContent, ""),
+			wantCalls: []string{"success"},
+		},
+		{
+			name: "should not call the success reader for non 2xx status",
+			readerFunc: func(calls *[]string) hardy.ReaderFunc {
+				return hardy.OnSuccess(func(response *http.Response) error {
+					*calls = append(*calls, "success")
+					return nil
+				})
+			},
+			response: newResponse(http.StatusMultipleChoices, ""),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls []string
+			err := tt.readerFunc(&calls)(tt.response)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReaderFunc() error = %v, errWant %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("ReaderFunc() calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
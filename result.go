@@ -0,0 +1,54 @@
+package hardy
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// maxRecordedIntervals is the max number of intervals kept by TryResult.
+const maxRecordedIntervals = 100
+
+// TryResult holds the diagnostics of the attempts performed by Client.TryWithResult.
+type TryResult struct {
+
+	// Attempts is the number of attempts performed.
+	Attempts int
+
+	// StatusCode is the HTTP status code of the last response got, if any.
+	StatusCode int
+
+	// BytesRead is the number of bytes read from the last response body, even if it was partially read.
+	BytesRead int64
+
+	// Intervals holds the interval waited before each retry, in order, including the adjustments made by the
+	// configured backoff floors, so the retry schedule might be asserted in tests. Only the first 100 intervals are
+	// kept, so the unlimited retries don't grow it without bounds.
+	Intervals []time.Duration
+
+	// response is the last response got, if any, with its body already closed, unless bodyOpen tells otherwise.
+	response *http.Response
+
+	// bodyOpen tells if the body of the response was left open by the manual body close, as the attempts succeeded.
+	bodyOpen bool
+}
+
+// countingReadCloser wraps a response body counting the bytes read from it, which might be read by some abandoned
+// ReaderFunc while the count is got.
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+// Read reads from the wrapped body, counting the bytes read.
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, err
+}
+
+// count gets the bytes read so far.
+func (r *countingReadCloser) count() int64 {
+	return atomic.LoadInt64(&r.n)
+}
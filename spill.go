@@ -0,0 +1,144 @@
+package hardy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// spillFiles holds the temporary files the request bodies of a call were spilled to, which must be removed once the
+// attempts are over.
+type spillFiles struct {
+	mu    sync.Mutex
+	files []*os.File
+}
+
+// spillBody buffers the body of the given request to a temporary file, as the given bytes already read followed by
+// the remaining ones, making it replayable by reopening the file.
+func (f *spillFiles) spillBody(req *http.Request, head []byte, remaining io.Reader) error {
+	file, err := f.create()
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(head); err != nil {
+		return fmt.Errorf("error while spilling request body: %w", err)
+	}
+	if _, err := io.Copy(file, remaining); err != nil {
+		return fmt.Errorf("error while spilling request body: %w", err)
+	}
+	return setFileBody(req, file)
+}
+
+// create creates a new temporary file, which is tracked to be removed once the attempts are over.
+func (f *spillFiles) create() (*os.File, error) {
+	file, err := os.CreateTemp("", "hardy-body-*")
+	if err != nil {
+		return nil, fmt.Errorf("error while creating request body file: %w", err)
+	}
+	f.mu.Lock()
+	f.files = append(f.files, file)
+	f.mu.Unlock()
+	return file, nil
+}
+
+// setFileBody sets the given temporary file as the body of the given request, making it replayable by reopening the
+// file.
+func setFileBody(req *http.Request, file *os.File) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error while spilling request body: %w", err)
+	}
+	name := file.Name()
+	req.Body = io.NopCloser(file)
+	req.GetBody = func() (io.ReadCloser, error) {
+		return os.Open(name)
+	}
+	return nil
+}
+
+// spillWriter buffers the bytes written to it in memory, unless they are more than the given threshold, if any, in
+// which case they are spilled to a temporary file tracked by the given spillFiles, as the bodies being compressed.
+type spillWriter struct {
+	files     *spillFiles
+	threshold int64
+	buf       bytes.Buffer
+	file      *os.File
+	size      int64
+}
+
+// Write writes the given bytes to the memory buffer, or to the temporary file once the threshold was exceeded.
+func (w *spillWriter) Write(p []byte) (int, error) {
+	if w.file == nil && w.threshold > 0 && w.size+int64(len(p)) > w.threshold {
+		file, err := w.files.create()
+		if err != nil {
+			return 0, err
+		}
+		w.file = file
+		if _, err := w.file.Write(w.buf.Bytes()); err != nil {
+			return 0, fmt.Errorf("error while spilling request body: %w", err)
+		}
+		w.buf.Reset()
+	}
+	var n int
+	var err error
+	if w.file != nil {
+		n, err = w.file.Write(p)
+	} else {
+		n, err = w.buf.Write(p)
+	}
+	w.size += int64(n)
+	return n, err
+}
+
+// setBody sets the bytes written as the replayable body of the given request, along with its length.
+func (w *spillWriter) setBody(req *http.Request) error {
+	req.ContentLength = w.size
+	if w.file != nil {
+		return setFileBody(req, w.file)
+	}
+	b := w.buf.Bytes()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	return nil
+}
+
+// largeBodySize gets the size of the body of the given request if it was spilled to a temporary file or is larger than
+// the body spill threshold, telling if so.
+func (c *Client) largeBodySize(req *http.Request) (int64, bool) {
+	if file, ok := req.Body.(*os.File); ok {
+		info, err := file.Stat()
+		if err != nil {
+			return req.ContentLength, true
+		}
+		return info.Size(), true
+	}
+	if c.bodySpillThreshold > 0 && req.ContentLength > c.bodySpillThreshold {
+		return req.ContentLength, true
+	}
+	return 0, false
+}
+
+// remove closes and removes the temporary files, which might be called more than once.
+func (f *spillFiles) remove() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var errs []error
+	for _, file := range f.files {
+		if err := file.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+			errs = append(errs, err)
+		}
+		if err := os.Remove(file.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	f.files = nil
+	if len(errs) > 0 {
+		return fmt.Errorf("error while removing request body files: %v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package hardy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// eventStreamContentType is the content type of server-sent event streams.
+	eventStreamContentType = "text/event-stream"
+
+	// acceptHeader is the header used to tell the content types accepted in the response.
+	acceptHeader = "Accept"
+
+	// lastEventIDHeader is the header used to resume a server-sent event stream from the last event received.
+	lastEventIDHeader = "Last-Event-ID"
+
+	// defaultSSEEventType is the type of server-sent events without an event field.
+	defaultSSEEventType = "message"
+)
+
+// errStreamEnded is the error returned when the server ends a server-sent event stream, which allows a new attempt.
+var errStreamEnded = errors.New("stream ended by the server")
+
+// SSEEvent defines a server-sent event, as dispatched by TrySSE.
+type SSEEvent struct {
+	// ID is the last event ID received so far, which is the one sent as Last-Event-ID when reconnecting.
+	ID string
+
+	// Event is the event type, which is "message" if the event has no event field.
+	Event string
+
+	// Data is the event data, with the values of multiple data fields joined by a line feed.
+	Data string
+}
+
+// SSEHandlerFunc defines the function called for each event read from a server-sent event stream. Returning an error
+// stops the streaming without new attempts.
+type SSEHandlerFunc func(event SSEEvent) error
+
+// sseReconnectKey is the context key of the reconnection interval of a server-sent event stream.
+type sseReconnectKey struct{}
+
+// sseStream holds the state of a server-sent event stream kept across reconnections.
+type sseStream struct {
+	mu          sync.Mutex
+	lastEventID string
+	reconnect   time.Duration
+}
+
+// TrySSE tries to perform the given request as per configurations, consuming its response body as a server-sent
+// event stream and calling the given SSEHandlerFunc for each event dispatched. If the stream breaks or the server ends
+// it, a new attempt is performed sending the last event ID received as the Last-Event-ID header, waiting the
+// reconnection interval given by the server through the retry field instead of the client backoff, if any. The
+// streaming stops once the server responds with 204 No Content, the handler returns an error, the new attempts are
+// exhausted, as by the max retries, or the given context is gone, so WithUnlimitedRetries suits long-lived streams.
+//
+// Responses with a non-2xx status code are not streamed, allowing new attempts only if the RetryPolicy allows, while
+// responses other than text/event-stream fail without new attempts. As with TryStream, the HTTP Client timeout and
+// the one given through WithAttemptTimeout also bound the streaming.
+func (c *Client) TrySSE(ctx context.Context, req *http.Request, handler SSEHandlerFunc) error {
+	if handler == nil {
+		return ErrNoReaderFuncFound
+	}
+
+	// The request is cloned, so the Last-Event-ID header can be set as events are received.
+	req = req.Clone(ctx)
+	if req.Header.Get(acceptHeader) == "" {
+		req.Header.Set(acceptHeader, eventStreamContentType)
+	}
+
+	stream := &sseStream{}
+	retryPolicy := c.snapshot().retryPolicy
+	readerFunc := func(response *http.Response) error {
+		if response.StatusCode == http.StatusNoContent {
+			return nil
+		}
+		if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+			err := fmt.Errorf("unexpected status code: %s", response.Status)
+			if retryPolicy(req, response) {
+				return err
+			}
+			return Permanent(err)
+		}
+		if mediaType, _, _ := mime.ParseMediaType(response.Header.Get(contentTypeHeader)); mediaType != eventStreamContentType {
+			return Permanent(fmt.Errorf("unexpected content type: %q", response.Header.Get(contentTypeHeader)))
+		}
+		err := stream.read(response.Body, handler)
+		if lastEventID := stream.lastID(); lastEventID != "" {
+			req.Header.Set(lastEventIDHeader, lastEventID)
+		} else {
+			req.Header.Del(lastEventIDHeader)
+		}
+		return err
+	}
+	_, err := c.try(context.WithValue(ctx, sseReconnectKey{}, stream), req, nil, readerFunc, nil, true)
+	return err
+}
+
+// read reads the events from the given server-sent event stream, calling the given dispatch function for each one,
+// until the stream breaks or ends, which returns errStreamEnded, so the client reconnects. Events not terminated by a
+// blank line are discarded, as the stream ended before they were complete.
+func (s *sseStream) read(body io.Reader, dispatch SSEHandlerFunc) error {
+	reader := bufio.NewReader(body)
+	s.mu.Lock()
+	idBuffer := s.lastEventID
+	s.mu.Unlock()
+	var eventType string
+	var data bytes.Buffer
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return errStreamEnded
+		}
+		if err != nil {
+			return fmt.Errorf("stream broken: %w", err)
+		}
+		line = bytes.TrimRight(line, "\r\n")
+
+		// A blank line dispatches the event, if it has some data.
+		if len(line) == 0 {
+			s.mu.Lock()
+			s.lastEventID = idBuffer
+			s.mu.Unlock()
+			if data.Len() == 0 {
+				eventType = ""
+				continue
+			}
+			event := SSEEvent{ID: idBuffer, Event: eventType, Data: string(bytes.TrimSuffix(data.Bytes(), []byte("\n")))}
+			if event.Event == "" {
+				event.Event = defaultSSEEventType
+			}
+			eventType = ""
+			data.Reset()
+			if dispatchErr := dispatch(event); dispatchErr != nil {
+				return Permanent(dispatchErr)
+			}
+			continue
+		}
+
+		// Lines starting with a colon are comments.
+		if line[0] == ':' {
+			continue
+		}
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "event":
+			eventType = string(value)
+		case "data":
+			data.Write(value)
+			data.WriteByte('\n')
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				idBuffer = string(value)
+			}
+		case "retry":
+			if ms, parseErr := strconv.ParseUint(string(value), 10, 63); parseErr == nil {
+				s.mu.Lock()
+				s.reconnect = time.Duration(ms) * time.Millisecond
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// lastID gets the last event ID received from the stream.
+func (s *sseStream) lastID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastEventID
+}
+
+// sseReconnectIntervalFrom gets the reconnection interval given by the server-sent event stream attached to the given
+// context, if any.
+func sseReconnectIntervalFrom(ctx context.Context) time.Duration {
+	stream, _ := ctx.Value(sseReconnectKey{}).(*sseStream)
+	if stream == nil {
+		return 0
+	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	return stream.reconnect
+}
@@ -0,0 +1,55 @@
+package hardy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StreamHandlerFunc defines the function called for each line read from a streamed response body, without the line
+// terminator. Returning an error stops the streaming without new attempts.
+type StreamHandlerFunc func(line []byte) error
+
+// TryStream tries to perform the given request as per configurations, keeping its response body open to consume it
+// as a line-delimited stream, as server-sent events or chunked responses, calling the given StreamHandlerFunc for each
+// line. If the stream breaks before it ends, a new attempt is performed using the same backoff as Try, which means
+// that the lines already handled might be received again. The streaming stops once the server ends it, the handler
+// returns an error or the given context is gone.
+//
+// Responses with a non-2xx status code are not streamed, allowing new attempts only if the RetryPolicy allows. Keep in mind that the HTTP Client timeout, as well as the one given through
+// WithAttemptTimeout, also bounds the streaming, so it should be disabled for long-lived streams.
+func (c *Client) TryStream(ctx context.Context, req *http.Request, handler StreamHandlerFunc) error {
+	if handler == nil {
+		return ErrNoReaderFuncFound
+	}
+	retryPolicy := c.snapshot().retryPolicy
+	readerFunc := func(response *http.Response) error {
+		if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+			err := fmt.Errorf("unexpected status code: %s", response.Status)
+			if retryPolicy(req, response) {
+				return err
+			}
+			return Permanent(err)
+		}
+		reader := bufio.NewReader(response.Body)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if handlerErr := handler(bytes.TrimRight(line, "\r\n")); handlerErr != nil {
+					return Permanent(handlerErr)
+				}
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("stream broken: %w", err)
+			}
+		}
+	}
+	_, err := c.try(withMethodAwareRetries(ctx), req, nil, readerFunc, nil, true)
+	return err
+}
@@ -0,0 +1,272 @@
+package hardy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/diegohordi/hardy"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_TryStream(t *testing.T) {
+	t.Parallel()
+	errStop := errors.New("stop")
+	tests := []struct {
+		name      string
+		handler   func(lines *[]string) hardy.StreamHandlerFunc
+		wantErr   bool
+		errWant   error
+		wantLines []string
+		wantCalls int32
+	}{
+		{
+			name: "should reconnect when the stream breaks",
+			handler: func(lines *[]string) hardy.StreamHandlerFunc {
+				return func(line []byte) error {
+					*lines = append(*lines, string(line))
+					return nil
+				}
+			},
+			wantLines: []string{"data: 1", "", "data: 2", "", "data: 3"},
+			wantCalls: 2,
+		},
+		{
+			name: "should stop when the handler fails",
+			handler: func(lines *[]string) hardy.StreamHandlerFunc {
+				return func(line []byte) error {
+					*lines = append(*lines, string(line))
+					return errStop
+				}
+			},
+			wantErr:   true,
+			errWant:   errStop,
+			wantLines: []string{"data: 1"},
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					// Announces more bytes than written, so the stream breaks.
+					w.Header().Set("Content-Length", "100")
+					_, _ = fmt.Fprint(w, "data: 1\r\n\r\n")
+					return
+				}
+				_, _ = fmt.Fprint(w, "data: 2\n\n")
+				w.(http.Flusher).Flush()
+				_, _ = fmt.Fprint(w, "data: 3")
+			}))
+			defer server.Close()
+
+			client, err := hardy.NewClient(
+				hardy.WithDebugDisabled(),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var lines []string
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			err = client.TryStream(context.TODO(), req, tt.handler(&lines))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TryStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.errWant) {
+				t.Errorf("TryStream() error = %v, errWant %v", err, tt.errWant)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("TryStream() lines = %q, want %q", lines, tt.wantLines)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("TryStream() calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_TryStream_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "data: 1\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	client, err := hardy.NewClient(
+		hardy.WithDebugDisabled(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	var once sync.Once
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	err = client.TryStream(ctx, req, func(line []byte) error {
+		once.Do(cancel)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("TryStream() error = %v, errWant %v", err, context.Canceled)
+	}
+}
+
+func TestClient_TrySSE(t *testing.T) {
+	t.Parallel()
+
+	errHandler := errors.New("handler error")
+
+	type request struct {
+		accept      string
+		lastEventID string
+	}
+
+	tests := []struct {
+		name          string
+		streams       []string
+		brokenStreams int
+		contentType   string
+		handlerErr    error
+		wantEvents    []hardy.SSEEvent
+		wantRequests  []request
+		wantIntervals []time.Duration
+		wantErr       bool
+		errWant       error
+	}{
+		{
+			name: "should dispatch the events ignoring comments and incomplete ones",
+			streams: []string{
+				": comment\nevent: greeting\ndata: hello\ndata: world\n\nid: 1\ndata:plain\r\n\r\ndata: incomplete",
+			},
+			wantEvents: []hardy.SSEEvent{
+				{Event: "greeting", Data: "hello\nworld"},
+				{ID: "1", Event: "message", Data: "plain"},
+			},
+			wantRequests:  []request{{accept: "text/event-stream"}, {accept: "text/event-stream", lastEventID: "1"}},
+			wantIntervals: []time.Duration{time.Millisecond},
+		},
+		{
+			name:         "should stop without reconnecting due to a 204 response",
+			wantRequests: []request{{accept: "text/event-stream"}},
+		},
+		{
+			name: "should reconnect with the last event ID waiting the retry interval given by the server",
+			streams: []string{
+				"retry: 5\nid: 1\ndata: first\n\nid: 2\ndata: broken",
+				"id: 2\ndata: second\n\n",
+			},
+			brokenStreams: 1,
+			wantEvents: []hardy.SSEEvent{
+				{ID: "1", Event: "message", Data: "first"},
+				{ID: "2", Event: "message", Data: "second"},
+			},
+			wantRequests: []request{
+				{accept: "text/event-stream"},
+				{accept: "text/event-stream", lastEventID: "1"},
+				{accept: "text/event-stream", lastEventID: "2"},
+			},
+			wantIntervals: []time.Duration{5 * time.Millisecond, 5 * time.Millisecond},
+		},
+		{
+			name:         "should fail without reconnecting due to an unexpected content type",
+			streams:      []string{"data: first\n\n"},
+			contentType:  "application/json",
+			wantRequests: []request{{accept: "text/event-stream"}},
+			wantErr:      true,
+		},
+		{
+			name:         "should stop without reconnecting due to a handler error",
+			streams:      []string{"data: first\n\ndata: second\n\n"},
+			handlerErr:   errHandler,
+			wantEvents:   []hardy.SSEEvent{{Event: "message", Data: "first"}},
+			wantRequests: []request{{accept: "text/event-stream"}},
+			wantErr:      true,
+			errWant:      errHandler,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var requests []request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests = append(requests, request{accept: r.Header.Get("Accept"), lastEventID: r.Header.Get("Last-Event-ID")})
+				attempt := len(requests)
+				mu.Unlock()
+				if attempt > len(tt.streams) {
+					// Tells the client to stop reconnecting once the streams are over.
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				contentType := tt.contentType
+				if contentType == "" {
+					contentType = "text/event-stream; charset=utf-8"
+				}
+				w.Header().Set("Content-Type", contentType)
+				if attempt <= tt.brokenStreams {
+					// Announces a longer body, so the stream breaks.
+					w.Header().Set("Content-Length", "1000")
+				}
+				_, _ = fmt.Fprint(w, tt.streams[attempt-1])
+			}))
+			defer server.Close()
+
+			clock := &FakeClock{now: time.Now()}
+			client, err := hardy.NewClient(
+				hardy.WithDebugDisabled(),
+				hardy.WithClock(clock),
+				hardy.WithWaitInterval(1*time.Millisecond),
+				hardy.WithMaxInterval(1*time.Millisecond),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var events []hardy.SSEEvent
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			err = client.TrySSE(context.TODO(), req, func(event hardy.SSEEvent) error {
+				events = append(events, event)
+				return tt.handlerErr
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TrySSE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errWant != nil && !errors.Is(err, tt.errWant) {
+				t.Errorf("TrySSE() error = %v, errWant %v", err, tt.errWant)
+			}
+			if !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Errorf("TrySSE() events = %+v, want %+v", events, tt.wantEvents)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("TrySSE() requests = %+v, want %+v", requests, tt.wantRequests)
+			}
+			if got := clock.Intervals(); !reflect.DeepEqual(got, tt.wantIntervals) {
+				t.Errorf("TrySSE() intervals = %v, want %v", got, tt.wantIntervals)
+			}
+		})
+	}
+}